@@ -14,6 +14,13 @@ import (
 var prompt []string
 var theText []string
 var qaPairGenModel string // model to use
+var chunkTokens int
+var chunkOverlap int
+var judgeModel string
+var judgeMinScore int
+var resumeRunID string
+var qapairsConfigFile string
+var textDirs []string
 
 func newQapairCommand() *cobra.Command {
 	var qapairCmd = &cobra.Command{
@@ -39,7 +46,20 @@ func newQapairCommand() *cobra.Command {
 				serverConfig.FineTuning.QAPairGenModel = qaPairGenModel
 			}
 
-			return qapairs.Run(client, "n/a", "n/a", serverConfig.FineTuning.QAPairGenModel, prompt, theText)
+			return qapairs.Run(client, qapairs.RunOptions{
+				OwnerID:       "n/a",
+				SessionID:     "n/a",
+				Model:         serverConfig.FineTuning.QAPairGenModel,
+				Prompts:       prompt,
+				Texts:         theText,
+				ConfigFile:    qapairsConfigFile,
+				TextDirs:      textDirs,
+				ChunkTokens:   chunkTokens,
+				ChunkOverlap:  chunkOverlap,
+				JudgeModel:    judgeModel,
+				JudgeMinScore: judgeMinScore,
+				Resume:        resumeRunID,
+			})
 		},
 	}
 
@@ -52,5 +72,26 @@ func newQapairCommand() *cobra.Command {
 	qapairCmd.Flags().StringSliceVar(&theText, "text", []string{},
 		"Text(s) to use, defaults to all",
 	)
+	qapairCmd.Flags().StringVar(&qapairsConfigFile, "config", "",
+		"Config file with prompts and texts, overrides the built-in config",
+	)
+	qapairCmd.Flags().StringSliceVar(&textDirs, "texts-dir", []string{},
+		"Directory (or glob) to discover text files in recursively",
+	)
+	qapairCmd.Flags().IntVar(&chunkTokens, "chunk-tokens", 0,
+		"Split texts longer than this many (estimated) tokens, defaults to the config",
+	)
+	qapairCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 0,
+		"Overlap between chunks in (estimated) tokens, defaults to the config",
+	)
+	qapairCmd.Flags().StringVar(&judgeModel, "judge-model", "",
+		"Model to score the generated pairs with, pairs scoring too low are dropped. Disabled by default",
+	)
+	qapairCmd.Flags().IntVar(&judgeMinScore, "judge-min-score", 0,
+		"Minimum faithfulness and answerability score (1-5) to keep a pair, defaults to the config",
+	)
+	qapairCmd.Flags().StringVar(&resumeRunID, "resume", "",
+		"ID of an interrupted run to resume, only the queries it hasn't completed are run",
+	)
 	return qapairCmd
 }
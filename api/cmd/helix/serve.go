@@ -15,6 +15,7 @@ import (
 	"github.com/helixml/helix/api/pkg/controller"
 	"github.com/helixml/helix/api/pkg/controller/knowledge"
 	"github.com/helixml/helix/api/pkg/controller/knowledge/browser"
+	"github.com/helixml/helix/api/pkg/dataexport"
 	"github.com/helixml/helix/api/pkg/extract"
 	"github.com/helixml/helix/api/pkg/filestore"
 	"github.com/helixml/helix/api/pkg/gptscript"
@@ -27,6 +28,7 @@ import (
 	"github.com/helixml/helix/api/pkg/rag"
 	"github.com/helixml/helix/api/pkg/scheduler"
 	"github.com/helixml/helix/api/pkg/server"
+	"github.com/helixml/helix/api/pkg/sessionarchive"
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/stripe"
 	"github.com/helixml/helix/api/pkg/system"
@@ -185,11 +187,23 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return err
 	}
 
-	store, err := store.NewPostgresStore(cfg.Store)
+	postgresStore, err := store.NewPostgresStore(cfg.Store)
 	if err != nil {
 		return err
 	}
 
+	var store store.Store = postgresStore
+
+	var archiver *sessionarchive.Archiver
+	if cfg.SessionArchive.Enabled {
+		archiver, err = sessionarchive.New(cfg, postgresStore, fs)
+		if err != nil {
+			return fmt.Errorf("failed to create session archiver: %w", err)
+		}
+		// Loading archived sessions rehydrates them from the filestore
+		store = sessionarchive.NewStore(postgresStore, archiver)
+	}
+
 	ps, err := pubsub.New(cfg.PubSub.StoreDir)
 	if err != nil {
 		return err
@@ -204,7 +218,12 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return fmt.Errorf("failed to create keycloak authenticator: %v", err)
 	}
 
-	notifier, err := notification.New(&cfg.Notifications, keycloakAuthenticator)
+	webPush, err := notification.NewWebPush(&cfg.Notifications, store)
+	if err != nil {
+		return fmt.Errorf("failed to create web push notifier: %v", err)
+	}
+
+	notifier, err := notification.New(&cfg.Notifications, keycloakAuthenticator, store, webPush)
 	if err != nil {
 		return fmt.Errorf("failed to create notifier: %v", err)
 	}
@@ -325,6 +344,9 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return fmt.Errorf("unknown RAG provider: %s", cfg.RAG.DefaultRagProvider)
 	}
 
+	// Shared by the controller (queries) and the knowledge reconciler (indexing)
+	vectorStores := rag.NewVectorStores(store)
+
 	var appController *controller.Controller
 
 	controllerOptions := controller.Options{
@@ -340,6 +362,7 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		ProviderManager:      providerManager,
 		DataprepOpenAIClient: dataprepOpenAIClient,
 		Scheduler:            scheduler,
+		VectorStores:         vectorStores,
 	}
 
 	appController, err = controller.NewController(ctx, controllerOptions)
@@ -360,7 +383,7 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 		return fmt.Errorf("failed to create browser pool: %w", err)
 	}
 
-	knowledgeReconciler, err := knowledge.New(cfg, store, fs, extractor, ragClient, browserPool)
+	knowledgeReconciler, err := knowledge.New(cfg, store, fs, extractor, ragClient, vectorStores, browserPool)
 	if err != nil {
 		return err
 	}
@@ -375,6 +398,39 @@ func serve(cmd *cobra.Command, cfg *config.ServerConfig) error {
 	// Start integrations
 	go trigger.Start(ctx)
 
+	if cfg.DataExport.Enabled {
+		exportFS := fs
+		switch {
+		case cfg.DataExport.GCSBucket != "":
+			exportFS, err = filestore.NewGCSStorage(ctx, cfg.DataExport.GCSBucket, cfg.DataExport.GCSKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to create data export bucket client: %w", err)
+			}
+		case cfg.DataExport.S3Bucket != "":
+			exportFS, err = filestore.NewS3Storage(filestore.S3Options{
+				Endpoint:        cfg.DataExport.S3Endpoint,
+				Region:          cfg.DataExport.S3Region,
+				Bucket:          cfg.DataExport.S3Bucket,
+				AccessKeyID:     cfg.DataExport.S3AccessKeyID,
+				SecretAccessKey: cfg.DataExport.S3SecretAccessKey,
+				Insecure:        cfg.DataExport.S3Insecure,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create data export bucket client: %w", err)
+			}
+		}
+
+		exporter, err := dataexport.New(cfg, store, exportFS)
+		if err != nil {
+			return fmt.Errorf("failed to create data exporter: %w", err)
+		}
+		go exporter.Start(ctx)
+	}
+
+	if archiver != nil {
+		go archiver.Start(ctx)
+	}
+
 	stripe := stripe.NewStripe(
 		cfg.Stripe,
 		func(eventType types.SubscriptionEventType, user types.StripeUser) error {
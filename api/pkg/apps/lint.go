@@ -0,0 +1,549 @@
+package apps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/helixml/helix/api/pkg/tools"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// LintIssue is a single problem found in an app config file, Line and
+// Column point at the offending node (1-based, 0 if unknown)
+type LintIssue struct {
+	Line     int          `json:"line"`
+	Column   int          `json:"column"`
+	Path     string       `json:"path"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s: %s", i.Line, i.Column, i.Severity, i.Path, i.Message)
+}
+
+// LintOptions carries what the linter knows about the target Helix
+// installation. Checks that need a piece of information are skipped when
+// it is nil, so the linter can also run offline.
+type LintOptions struct {
+	// Secrets are the names of the secrets that can be referenced
+	// as ${NAME} in the config
+	Secrets []string
+	// ListModels returns the model IDs available from a provider, the
+	// empty provider is the server's default
+	ListModels func(provider types.Provider) ([]string, error)
+}
+
+// HasLintErrors returns true if any of the issues is an error
+func HasLintErrors(issues []LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == LintSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintFile validates an app config file without applying it
+func LintFile(filename string, opts *LintOptions) ([]LintIssue, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filename, err)
+	}
+
+	return Lint(data, filepath.Dir(filename), opts)
+}
+
+// Lint validates app config YAML (plain or CRD format). File references
+// in the config are resolved relative to basePath.
+func Lint(data []byte, basePath string, opts *LintOptions) ([]LintIssue, error) {
+	if opts == nil {
+		opts = &LintOptions{}
+	}
+
+	l := &linter{
+		basePath: basePath,
+		opts:     opts,
+		models:   make(map[types.Provider][]string),
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []LintIssue{{
+			Line:     yamlErrorLine(err),
+			Severity: LintSeverityError,
+			Message:  err.Error(),
+		}}, nil
+	}
+
+	if len(root.Content) == 0 {
+		l.add(nil, "", LintSeverityError, "file is empty")
+		return l.issues, nil
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		l.add(doc, "", LintSeverityError, "expected a mapping at the top level")
+		return l.issues, nil
+	}
+
+	spec := doc
+	prefix := ""
+	if isCRDNode(doc) {
+		l.checkFields(doc, reflect.TypeOf(types.AppHelixConfigCRD{}), "")
+		spec = mappingValue(doc, "spec")
+		prefix = "spec"
+	} else {
+		l.checkFields(doc, reflect.TypeOf(types.AppHelixConfig{}), "")
+	}
+
+	// Stop on structural errors, decoding would only repeat them
+	// without positions
+	if HasLintErrors(l.issues) {
+		return l.sorted(), nil
+	}
+
+	config, err := processConfig(data)
+	if err != nil {
+		l.add(doc, "", LintSeverityError, err.Error())
+		return l.sorted(), nil
+	}
+
+	l.checkApp(config, spec, prefix)
+	l.checkSecretReferences(doc, "")
+
+	return l.sorted(), nil
+}
+
+type linter struct {
+	basePath string
+	opts     *LintOptions
+	models   map[types.Provider][]string
+	issues   []LintIssue
+}
+
+func (l *linter) add(node *yaml.Node, path string, severity LintSeverity, format string, args ...interface{}) {
+	issue := LintIssue{
+		Path:     path,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	l.issues = append(l.issues, issue)
+}
+
+func (l *linter) sorted() []LintIssue {
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Line != l.issues[j].Line {
+			return l.issues[i].Line < l.issues[j].Line
+		}
+		return l.issues[i].Column < l.issues[j].Column
+	})
+	return l.issues
+}
+
+// checkFields walks the YAML node against the Go type it will be decoded
+// into, reporting unknown fields (which the YAML decoder silently drops)
+// and values of the wrong shape
+func (l *linter) checkFields(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// Empty values decode to the zero value
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			l.add(node, path, LintSeverityError, "expected a mapping, got %s", nodeKind(node))
+			return
+		}
+
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				l.add(key, joinPath(path, key.Value), LintSeverityWarning, "unknown field %q, it will be ignored", key.Value)
+				continue
+			}
+			l.checkFields(value, field.Type, joinPath(path, key.Value))
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			l.add(node, path, LintSeverityError, "expected a list, got %s", nodeKind(node))
+			return
+		}
+		for i, item := range node.Content {
+			l.checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			l.add(node, path, LintSeverityError, "expected a mapping, got %s", nodeKind(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			l.checkFields(value, t.Elem(), joinPath(path, key.Value))
+		}
+	case reflect.Interface:
+		// Anything goes
+	default:
+		if node.Kind != yaml.ScalarNode {
+			l.add(node, path, LintSeverityError, "expected a %s value, got %s", t.Kind(), nodeKind(node))
+			return
+		}
+		switch t.Kind() {
+		case reflect.Bool:
+			if _, err := strconv.ParseBool(node.Value); err != nil && node.Tag != "!!bool" {
+				l.add(node, path, LintSeverityError, "expected a boolean, got %q", node.Value)
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if node.Tag != "!!int" {
+				l.add(node, path, LintSeverityError, "expected an integer, got %q", node.Value)
+			}
+		case reflect.Float32, reflect.Float64:
+			if node.Tag != "!!int" && node.Tag != "!!float" {
+				l.add(node, path, LintSeverityError, "expected a number, got %q", node.Value)
+			}
+		}
+	}
+}
+
+func (l *linter) checkApp(config *types.AppHelixConfig, spec *yaml.Node, prefix string) {
+	if config.Name == "" {
+		l.add(spec, prefix, LintSeverityError, "name is required")
+	}
+
+	if len(config.Assistants) == 0 {
+		l.add(spec, prefix, LintSeverityWarning, "no assistants are defined")
+	}
+
+	for i := range config.Assistants {
+		path := fmt.Sprintf("%s[%d]", joinPath(prefix, "assistants"), i)
+		node := lookupNode(spec, "assistants", i)
+		l.checkAssistant(&config.Assistants[i], node, path)
+	}
+}
+
+func (l *linter) checkAssistant(assistant *types.AssistantConfig, node *yaml.Node, path string) {
+	l.checkModel(assistant.Provider, assistant.Model, lookupNode(node, "model"), joinPath(path, "model"))
+
+	for i, api := range assistant.APIs {
+		apiPath := fmt.Sprintf("%s[%d]", joinPath(path, "apis"), i)
+		apiNode := lookupNode(node, "apis", i)
+
+		if api.Name == "" {
+			l.add(apiNode, apiPath, LintSeverityError, "name is required")
+		}
+		if api.Description == "" {
+			l.add(apiNode, apiPath, LintSeverityError, "description is required, it tells the model when to use the API")
+		}
+		if api.URL == "" {
+			l.add(apiNode, apiPath, LintSeverityError, "url is required")
+		}
+		if api.Schema == "" {
+			l.add(apiNode, apiPath, LintSeverityError, "schema is required")
+			continue
+		}
+
+		l.checkSchema(api.Schema, lookupNode(apiNode, "schema"), joinPath(apiPath, "schema"))
+	}
+
+	for i, script := range assistant.GPTScripts {
+		scriptPath := fmt.Sprintf("%s[%d]", joinPath(path, "gptscripts"), i)
+		scriptNode := lookupNode(node, "gptscripts", i)
+
+		if script.Description == "" {
+			l.add(scriptNode, scriptPath, LintSeverityError, "description is required, it tells the model when to use the script")
+		}
+
+		switch {
+		case script.File != "":
+			matches, err := filepath.Glob(filepath.Join(l.basePath, script.File))
+			if err != nil {
+				l.add(lookupNode(scriptNode, "file"), joinPath(scriptPath, "file"), LintSeverityError, "invalid file pattern: %s", err)
+			} else if len(matches) == 0 {
+				l.add(lookupNode(scriptNode, "file"), joinPath(scriptPath, "file"), LintSeverityError, "no files match %q", script.File)
+			}
+		case script.Content == "":
+			l.add(scriptNode, scriptPath, LintSeverityError, "either file or content is required")
+		default:
+			if script.Name == "" {
+				l.add(scriptNode, scriptPath, LintSeverityError, "name is required")
+			}
+		}
+	}
+
+	for i, zapier := range assistant.Zapier {
+		zapierPath := fmt.Sprintf("%s[%d]", joinPath(path, "zapier"), i)
+		zapierNode := lookupNode(node, "zapier", i)
+
+		if zapier.Name == "" {
+			l.add(zapierNode, zapierPath, LintSeverityError, "name is required")
+		}
+		if zapier.APIKey == "" {
+			l.add(zapierNode, zapierPath, LintSeverityError, "api_key is required")
+		}
+		if zapier.Model == "" {
+			l.add(zapierNode, zapierPath, LintSeverityError, "model is required")
+		}
+	}
+
+	knowledgeNames := make(map[string]bool)
+	for i, knowledge := range assistant.Knowledge {
+		if knowledge == nil {
+			continue
+		}
+
+		knowledgePath := fmt.Sprintf("%s[%d]", joinPath(path, "knowledge"), i)
+		knowledgeNode := lookupNode(node, "knowledge", i)
+
+		switch {
+		case knowledge.Name == "":
+			l.add(knowledgeNode, knowledgePath, LintSeverityError, "name is required")
+		case knowledgeNames[knowledge.Name]:
+			l.add(lookupNode(knowledgeNode, "name"), joinPath(knowledgePath, "name"), LintSeverityError, "duplicate knowledge name %q", knowledge.Name)
+		}
+		knowledgeNames[knowledge.Name] = true
+
+		source := knowledge.Source
+		if source.Filestore == nil && source.S3 == nil && source.GCS == nil && source.Web == nil && source.Content == nil {
+			l.add(knowledgeNode, knowledgePath, LintSeverityError, "source is required")
+		}
+	}
+
+	for i, test := range assistant.Tests {
+		for j, step := range test.Steps {
+			if step.Prompt == "" {
+				stepPath := fmt.Sprintf("%s[%d].steps[%d]", joinPath(path, "tests"), i, j)
+				l.add(lookupNode(node, "tests", i, "steps", j), stepPath, LintSeverityError, "prompt is required")
+			}
+		}
+	}
+}
+
+func (l *linter) checkModel(provider types.Provider, modelName string, node *yaml.Node, path string) {
+	if modelName == "" || l.opts.ListModels == nil {
+		return
+	}
+
+	// Legacy aliases are resolved by the helix provider
+	if (provider == "" || provider == types.ProviderHelix) && strings.HasPrefix(modelName, "helix-") {
+		return
+	}
+
+	models, ok := l.models[provider]
+	if !ok {
+		var err error
+		models, err = l.opts.ListModels(provider)
+		if err != nil {
+			l.add(node, path, LintSeverityWarning, "could not check model %q: %s", modelName, err)
+			return
+		}
+		l.models[provider] = models
+	}
+
+	for _, m := range models {
+		if m == modelName {
+			return
+		}
+	}
+
+	if provider == "" {
+		l.add(node, path, LintSeverityError, "model %q is not available", modelName)
+		return
+	}
+	l.add(node, path, LintSeverityError, "model %q is not available from provider %q", modelName, provider)
+}
+
+func (l *linter) checkSchema(schema string, node *yaml.Node, path string) {
+	lower := strings.ToLower(schema)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		// Remote schemas are only fetched on apply
+		return
+	}
+
+	content, err := processSchemaContent(schema, l.basePath)
+	if err != nil {
+		l.add(node, path, LintSeverityError, "%s", err)
+		return
+	}
+
+	actions, err := tools.GetActionsFromSchema(content)
+	if err != nil {
+		l.add(node, path, LintSeverityError, "invalid schema: %s", err)
+		return
+	}
+
+	if len(actions) == 0 {
+		l.add(node, path, LintSeverityWarning, "schema does not define any operations")
+	}
+}
+
+// secretReferencePattern matches ${NAME} and ${NAME:-default} style references
+// that are substituted with the user's secrets at runtime
+var secretReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?-[^}]*)?\}`)
+
+func (l *linter) checkSecretReferences(node *yaml.Node, path string) {
+	if l.opts.Secrets == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		for _, match := range secretReferencePattern.FindAllStringSubmatch(node.Value, -1) {
+			// References with a default value are always resolvable
+			if match[2] != "" {
+				continue
+			}
+			if !containsString(l.opts.Secrets, match[1]) {
+				l.add(node, path, LintSeverityError, "references undefined secret %q", match[1])
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			l.checkSecretReferences(node.Content[i+1], joinPath(path, node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			l.checkSecretReferences(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// yamlFields maps YAML keys to struct fields the same way yaml.v2 does
+// when decoding, untagged fields use their lowercased name
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.ToLower(field.Name)
+		if tag, ok := field.Tag.Lookup("yaml"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func isCRDNode(node *yaml.Node) bool {
+	return mappingValue(node, "apiVersion") != nil &&
+		mappingValue(node, "kind") != nil &&
+		mappingValue(node, "spec") != nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lookupNode follows the path of mapping keys and sequence indexes,
+// returning the deepest node found so issues about missing fields still
+// point at their parent
+func lookupNode(node *yaml.Node, path ...interface{}) *yaml.Node {
+	for _, p := range path {
+		if node == nil {
+			return nil
+		}
+
+		var next *yaml.Node
+		switch p := p.(type) {
+		case string:
+			next = mappingValue(node, p)
+		case int:
+			if node.Kind == yaml.SequenceNode && p < len(node.Content) {
+				next = node.Content[p]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+func yamlErrorLine(err error) int {
+	match := yamlErrorLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
@@ -0,0 +1,159 @@
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintTestSchema = `openapi: 3.0.0
+info:
+  title: Weather API
+  version: 1.0.0
+paths:
+  /weather:
+    get:
+      operationId: getWeather
+      summary: Get the weather
+      responses:
+        '200':
+          description: OK
+`
+
+func TestLint(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "weather.yaml"), []byte(lintTestSchema), 0644))
+
+	opts := &LintOptions{
+		Secrets: []string{"WEATHER_KEY"},
+		ListModels: func(provider types.Provider) ([]string, error) {
+			if provider == types.ProviderOpenAI {
+				return []string{"gpt-4o"}, nil
+			}
+			return []string{"llama3:instruct"}, nil
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		yamlData string
+		expected []LintIssue
+	}{
+		{
+			name: "valid config",
+			yamlData: `name: weather
+assistants:
+- name: forecaster
+  model: llama3:instruct
+  apis:
+  - name: weather
+    description: Weather lookups
+    url: https://example.com
+    schema: weather.yaml
+    headers:
+      Authorization: Bearer ${WEATHER_KEY}
+`,
+		},
+		{
+			name: "valid CRD",
+			yamlData: `apiVersion: app.aispec.org/v1alpha1
+kind: AIApp
+metadata:
+  name: weather
+spec:
+  assistants:
+  - provider: openai
+    model: gpt-4o
+`,
+		},
+		{
+			name: "unknown field and wrong shape",
+			yamlData: `name: weather
+assistants:
+- model: llama3:instruct
+  system_promt: hello
+  apis: nope
+`,
+			expected: []LintIssue{
+				{Line: 4, Column: 3, Path: "assistants[0].system_promt", Severity: LintSeverityWarning, Message: `unknown field "system_promt", it will be ignored`},
+				{Line: 5, Column: 9, Path: "assistants[0].apis", Severity: LintSeverityError, Message: `expected a list, got "nope"`},
+			},
+		},
+		{
+			name: "unknown field in CRD spec",
+			yamlData: `apiVersion: app.aispec.org/v1alpha1
+kind: AIApp
+metadata:
+  name: weather
+spec:
+  assistant:
+  - model: llama3:instruct
+`,
+			expected: []LintIssue{
+				{Line: 6, Column: 3, Path: "spec.assistant", Severity: LintSeverityWarning, Message: `unknown field "assistant", it will be ignored`},
+				{Line: 6, Column: 3, Path: "spec", Severity: LintSeverityWarning, Message: "no assistants are defined"},
+			},
+		},
+		{
+			name: "semantic errors",
+			yamlData: `assistants:
+- provider: openai
+  model: gpt-5
+  apis:
+  - name: weather
+    schema: |
+      not: openapi
+  knowledge:
+  - name: docs
+    source:
+      web:
+        urls: [https://example.com]
+  - name: docs
+  tests:
+  - steps:
+    - expected_output: sunny
+  system_prompt: Use ${MISSING} and ${OPTIONAL:-x}
+`,
+			expected: []LintIssue{
+				{Line: 1, Column: 1, Path: "", Severity: LintSeverityError, Message: "name is required"},
+				{Line: 3, Column: 10, Path: "assistants[0].model", Severity: LintSeverityError, Message: `model "gpt-5" is not available from provider "openai"`},
+				{Line: 5, Column: 5, Path: "assistants[0].apis[0]", Severity: LintSeverityError, Message: "description is required, it tells the model when to use the API"},
+				{Line: 5, Column: 5, Path: "assistants[0].apis[0]", Severity: LintSeverityError, Message: "url is required"},
+				{Line: 6, Column: 13, Path: "assistants[0].apis[0].schema", Severity: LintSeverityWarning, Message: "schema does not define any operations"},
+				{Line: 13, Column: 11, Path: "assistants[0].knowledge[1].name", Severity: LintSeverityError, Message: `duplicate knowledge name "docs"`},
+				{Line: 13, Column: 5, Path: "assistants[0].knowledge[1]", Severity: LintSeverityError, Message: "source is required"},
+				{Line: 16, Column: 7, Path: "assistants[0].tests[0].steps[0]", Severity: LintSeverityError, Message: "prompt is required"},
+				{Line: 17, Column: 18, Path: "assistants[0].system_prompt", Severity: LintSeverityError, Message: `references undefined secret "MISSING"`},
+			},
+		},
+		{
+			name:     "invalid yaml",
+			yamlData: "name: weather\nassistants: [\n",
+			expected: []LintIssue{
+				{Line: 2, Severity: LintSeverityError, Message: "yaml: line 2: did not find expected node content"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues, err := Lint([]byte(tc.yamlData), tmpDir, opts)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expected, issues)
+		})
+	}
+}
+
+func TestLint_Offline(t *testing.T) {
+	issues, err := Lint([]byte(`name: weather
+assistants:
+- model: anything
+  system_prompt: ${NOT_CHECKED}
+`), t.TempDir(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
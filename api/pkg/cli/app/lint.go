@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/helixml/helix/api/pkg/apps"
+	"github.com/helixml/helix/api/pkg/client"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringP("filename", "f", "", "Filename to lint")
+	lintCmd.Flags().Bool("offline", false, "Skip checks that need the Helix API (models and secrets)")
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validate an application config file",
+	Long: `Validate an application config file without applying it. Reports unknown fields,
+invalid tool definitions, unavailable models and references to secrets that don't exist.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		filename, err := cmd.Flags().GetString("filename")
+		if err != nil {
+			return err
+		}
+
+		if filename == "" {
+			return fmt.Errorf("filename is required")
+		}
+
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			return err
+		}
+
+		opts := &apps.LintOptions{}
+
+		if !offline {
+			apiClient, err := client.NewClientFromEnv()
+			if err != nil {
+				return err
+			}
+
+			secrets, err := apiClient.ListSecrets(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list secrets: %w", err)
+			}
+
+			opts.Secrets = []string{}
+			for _, secret := range secrets {
+				opts.Secrets = append(opts.Secrets, secret.Name)
+			}
+
+			opts.ListModels = func(provider types.Provider) ([]string, error) {
+				models, err := apiClient.ListModels(cmd.Context(), provider)
+				if err != nil {
+					return nil, err
+				}
+
+				var ids []string
+				for _, m := range models {
+					ids = append(ids, m.ID)
+				}
+				return ids, nil
+			}
+		}
+
+		issues, err := apps.LintFile(filename, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", filename, issue)
+		}
+
+		if apps.HasLintErrors(issues) {
+			return fmt.Errorf("%s has errors", filename)
+		}
+
+		return nil
+	},
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -125,6 +126,8 @@ func (mcps *ModelContextProtocolServer) Start() error {
 		s.AddTool(mt.tool, mt.handler)
 	}
 
+	mcps.addMemories(s)
+
 	// Start the server
 	if err := server.ServeStdio(s); err != nil {
 		fmt.Printf("Server error: %v\n", err)
@@ -315,3 +318,67 @@ func (mcps *ModelContextProtocolServer) getKnowledgeToolHandler(knowledgeID stri
 		return mcp.NewToolResultText(string(resultsJSON)), nil
 	}
 }
+
+const saveMemoryToolName = "save_memory"
+
+// addMemories lets agents read the notes saved for the app as a resource and
+// save new ones (facts about the codebase, decisions made) with a tool, the
+// notes are also added to the user's future sessions with the app
+func (mcps *ModelContextProtocolServer) addMemories(s *server.MCPServer) {
+	s.AddResource(mcp.NewResource(
+		fmt.Sprintf("helix://apps/%s/memories", mcps.appID),
+		"Memories",
+		mcp.WithResourceDescription("Notes saved from previous sessions with this app, e.g. facts about the codebase and previous decisions"),
+		mcp.WithMIMEType("text/plain"),
+	), mcps.memoriesResourceHandler)
+
+	s.AddTool(mcp.NewTool(saveMemoryToolName,
+		mcp.WithDescription("Save a short note (a fact about the codebase, a decision made) so it is available in future sessions. Do not save secrets."),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("The note to save, keep it short and self-contained"),
+		),
+	), mcps.saveMemoryToolHandler)
+}
+
+func (mcps *ModelContextProtocolServer) memoriesResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	memories, err := mcps.apiClient.ListAgentMemories(ctx, mcps.appID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list memories")
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for _, memory := range memories {
+		sb.WriteString("- ")
+		sb.WriteString(memory.Content)
+		sb.WriteString("\n")
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "text/plain",
+			},
+			Text: sb.String(),
+		},
+	}, nil
+}
+
+func (mcps *ModelContextProtocolServer) saveMemoryToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, ok := request.Params.Arguments["content"].(string)
+	if !ok || content == "" {
+		return mcp.NewToolResultError("content is required"), nil
+	}
+
+	memory, err := mcps.apiClient.CreateAgentMemory(ctx, mcps.appID, &types.AgentMemoryRequest{
+		Content: content,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to save memory")
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Saved memory %s", memory.ID)), nil
+}
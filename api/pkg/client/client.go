@@ -15,6 +15,7 @@ import (
 
 	"github.com/helixml/helix/api/pkg/config"
 	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/model"
 	"github.com/helixml/helix/api/pkg/types"
 )
 
@@ -38,6 +39,11 @@ type Client interface {
 	UpdateSecret(ctx context.Context, id string, secret *types.Secret) (*types.Secret, error)
 	DeleteSecret(ctx context.Context, id string) error
 
+	ListAgentMemories(ctx context.Context, appID string) ([]*types.AgentMemory, error)
+	CreateAgentMemory(ctx context.Context, appID string, req *types.AgentMemoryRequest) (*types.AgentMemory, error)
+
+	ListModels(ctx context.Context, provider types.Provider) ([]model.OpenAIModel, error)
+
 	ListKnowledgeVersions(ctx context.Context, f *KnowledgeVersionsFilter) ([]*types.KnowledgeVersion, error)
 
 	FilestoreList(ctx context.Context, path string) ([]filestore.Item, error)
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ListAgentMemories lists the notes saved for the app by the calling user or their agents
+func (c *HelixClient) ListAgentMemories(ctx context.Context, appID string) ([]*types.AgentMemory, error) {
+	var memories []*types.AgentMemory
+	err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("/apps/%s/memories", appID), nil, &memories)
+	if err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+// CreateAgentMemory saves a note that is added to future sessions with the app
+func (c *HelixClient) CreateAgentMemory(ctx context.Context, appID string, req *types.AgentMemoryRequest) (*types.AgentMemory, error) {
+	bts, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal memory: %w", err)
+	}
+
+	var memory types.AgentMemory
+	err = c.makeRequest(ctx, http.MethodPost, fmt.Sprintf("/apps/%s/memories", appID), bytes.NewBuffer(bts), &memory)
+	if err != nil {
+		return nil, err
+	}
+	return &memory, nil
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/helixml/helix/api/pkg/model"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ListModels retrieves the models available for the provider, an empty
+// provider lists the models of the server's default inference provider
+func (c *HelixClient) ListModels(ctx context.Context, provider types.Provider) ([]model.OpenAIModel, error) {
+	// Models are served from the OpenAI compatible API which lives
+	// outside of /api/v1
+	root := *c
+	root.url = strings.TrimSuffix(c.url, "/api/v1")
+
+	path := "/v1/models"
+	if provider != "" {
+		query := url.Values{}
+		query.Add("provider", string(provider))
+		path += "?" + query.Encode()
+	}
+
+	var models model.OpenAIModelsList
+	err := root.makeRequest(ctx, http.MethodGet, path, nil, &models)
+	if err != nil {
+		return nil, err
+	}
+	return models.Models, nil
+}
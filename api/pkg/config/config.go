@@ -29,6 +29,9 @@ type ServerConfig struct {
 	Apps               Apps
 	GPTScript          GPTScript
 	Triggers           Triggers
+	DataExport         DataExport
+	SessionScratch     SessionScratch
+	SessionArchive     SessionArchive
 }
 
 func LoadServerConfig() (ServerConfig, error) {
@@ -103,11 +106,20 @@ type Keycloak struct {
 // Notifications is used for sending notifications to users when certain events happen
 // such as finetuning starting or completing.
 type Notifications struct {
-	AppURL string `envconfig:"APP_URL" default:"https://app.tryhelix.ai"`
-	Email  EmailConfig
+	AppURL  string `envconfig:"APP_URL" default:"https://app.tryhelix.ai"`
+	Email   EmailConfig
+	WebPush WebPushConfig
 	// TODO: Slack, Discord, etc.
 }
 
+type WebPushConfig struct {
+	// Base64url encoded P-256 private key, e.g. the private key from
+	// `npx web-push generate-vapid-keys`. Web Push is disabled when empty.
+	VAPIDPrivateKey string `envconfig:"WEB_PUSH_VAPID_PRIVATE_KEY"`
+	// Contact for push services, a mailto: or https: URL. Defaults to the app URL.
+	Subject string `envconfig:"WEB_PUSH_SUBJECT"`
+}
+
 type EmailConfig struct {
 	SenderAddress string `envconfig:"EMAIL_SENDER_ADDRESS" default:"chris@helix.ml"`
 
@@ -220,6 +232,24 @@ type Controller struct {
 	SchedulingDecisionBufferSize int `envconfig:"SCHEDULING_DECISION_BUFFER_SIZE" default:"10" description:"How many scheduling decisions to buffer before we start dropping them."`
 }
 
+// SessionScratch limits the temporary per-session blob namespace
+type SessionScratch struct {
+	MaxObjectSize  int64         `envconfig:"SESSION_SCRATCH_MAX_OBJECT_SIZE" default:"10485760" description:"Maximum size in bytes of a single scratch object."`
+	MaxSessionSize int64         `envconfig:"SESSION_SCRATCH_MAX_SESSION_SIZE" default:"52428800" description:"Maximum total size in bytes of all scratch objects in a session."`
+	TTL            time.Duration `envconfig:"SESSION_SCRATCH_TTL" default:"24h" description:"How long a scratch object is kept after it was last written."`
+}
+
+// SessionArchive moves the interactions of sessions that haven't been updated
+// for a while into the filestore, keeping a summary row in Postgres for listing.
+// Archived sessions are rehydrated when they are opened again.
+type SessionArchive struct {
+	Enabled   bool          `envconfig:"SESSION_ARCHIVE_ENABLED" default:"false" description:"Enable archival of old session interactions to the filestore."`
+	Retention time.Duration `envconfig:"SESSION_ARCHIVE_RETENTION" default:"2160h" description:"Sessions not updated for this long are archived."`
+	Interval  time.Duration `envconfig:"SESSION_ARCHIVE_INTERVAL" default:"1h" description:"How often to look for sessions to archive."`
+	Path      string        `envconfig:"SESSION_ARCHIVE_PATH" default:"session-archive" description:"The filestore folder, under the global prefix, to write archived sessions to."`
+	BatchSize int           `envconfig:"SESSION_ARCHIVE_BATCH_SIZE" default:"100" description:"Maximum sessions to archive per run."`
+}
+
 type FileStore struct {
 	Type         types.FileStoreType `envconfig:"FILESTORE_TYPE" default:"fs" description:"What type of filestore should we use (fs | gcs)."`
 	LocalFSPath  string              `envconfig:"FILESTORE_LOCALFS_PATH" default:"/tmp/helix/filestore" description:"The local path that is the root for the local fs filestore."`
@@ -254,6 +284,12 @@ type WebServer struct {
 	FrontendURL string `envconfig:"FRONTEND_URL" default:"http://frontend:8081" description:""`
 
 	RunnerToken string `envconfig:"RUNNER_TOKEN" description:"The token for runner auth."`
+	// only enable when the api server is behind a proxy that sets X-Forwarded-For,
+	// otherwise clients can spoof their IP for API key allowlists
+	TrustProxyHeaders bool `envconfig:"TRUST_PROXY_HEADERS" default:"false" description:"Use X-Forwarded-For to find the client IP."`
+	// the client IP is taken this many entries from the end of X-Forwarded-For,
+	// set it to the number of proxies in front of the api server
+	TrustedProxyHops int `envconfig:"TRUSTED_PROXY_HOPS" default:"1" description:"The number of trusted proxies that append to X-Forwarded-For."`
 	// a list of keycloak ids that are considered admins
 	// if the string '*' is included it means ALL users
 	AdminIDs []string `envconfig:"ADMIN_USER_IDS" description:"Keycloak admin IDs."`
@@ -306,6 +342,10 @@ type Apps struct {
 	Enabled  bool           `envconfig:"APPS_ENABLED" default:"true" description:"Enable apps."` // Enable/disable apps for the server
 	Provider types.Provider `envconfig:"APPS_PROVIDER" default:"togetherai" description:"Which LLM provider to use for apps."`
 	Model    string         `envconfig:"APPS_MODEL" default:"mistralai/Mixtral-8x7B-Instruct-v0.1" description:"Which LLM model to use for apps."` // gpt-4-1106-preview
+
+	// agent memories are injected into the system prompt so they are kept small
+	MaxMemoryLength int `envconfig:"APPS_MAX_MEMORY_LENGTH" default:"2000" description:"Maximum length in characters of a single agent memory."`
+	MaxMemories     int `envconfig:"APPS_MAX_MEMORIES" default:"50" description:"Maximum number of agent memories per app and user."`
 }
 
 type GPTScript struct {
@@ -335,3 +375,25 @@ type Discord struct {
 type Cron struct {
 	Enabled bool `envconfig:"CRON_ENABLED" default:"true"`
 }
+
+// DataExport periodically writes usage, session and audit tables into the
+// filestore, or a GCS or S3 bucket owned by the customer, so they can be loaded
+// into an external warehouse (e.g. BigQuery external tables over the GCS bucket)
+type DataExport struct {
+	Enabled   bool          `envconfig:"DATA_EXPORT_ENABLED" default:"false" description:"Enable periodic export of usage and session tables."`
+	Interval  time.Duration `envconfig:"DATA_EXPORT_INTERVAL" default:"1h" description:"How often to export new rows."`
+	Format    string        `envconfig:"DATA_EXPORT_FORMAT" default:"csv" description:"Export file format (csv | jsonl | parquet)."`
+	Path      string        `envconfig:"DATA_EXPORT_PATH" default:"exports" description:"The filestore folder, under the global prefix, to write exports to."`
+	Tables    []string      `envconfig:"DATA_EXPORT_TABLES" default:"llm_calls,sessions,script_runs" description:"Which tables to export (llm_calls | sessions | script_runs)."`
+	BatchSize int           `envconfig:"DATA_EXPORT_BATCH_SIZE" default:"1000" description:"Maximum rows per export file."`
+
+	GCSBucket  string `envconfig:"DATA_EXPORT_GCS_BUCKET" description:"Write exports to this GCS bucket instead of the filestore."`
+	GCSKeyFile string `envconfig:"DATA_EXPORT_GCS_KEY_FILE" description:"Service account key file with write access to the export bucket."`
+
+	S3Bucket          string `envconfig:"DATA_EXPORT_S3_BUCKET" description:"Write exports to this S3 bucket instead of the filestore."`
+	S3Endpoint        string `envconfig:"DATA_EXPORT_S3_ENDPOINT" description:"S3 compatible endpoint, defaults to AWS."`
+	S3Region          string `envconfig:"DATA_EXPORT_S3_REGION" description:"Region of the export bucket."`
+	S3AccessKeyID     string `envconfig:"DATA_EXPORT_S3_ACCESS_KEY_ID" description:"Access key with write access to the export bucket, the AWS environment or instance role is used when empty."`
+	S3SecretAccessKey string `envconfig:"DATA_EXPORT_S3_SECRET_ACCESS_KEY" description:"Secret for the export bucket access key."`
+	S3Insecure        bool   `envconfig:"DATA_EXPORT_S3_INSECURE" default:"false" description:"Connect to the S3 endpoint over plain HTTP (e.g. a local MinIO)."`
+}
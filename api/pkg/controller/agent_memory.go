@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ErrInvalidAgentMemory is returned for memories that are empty, too long or
+// over the per app limit
+var ErrInvalidAgentMemory = errors.New("invalid agent memory")
+
+const agentMemoryPromptHeader = "Notes saved from previous sessions with this user, use them if they are relevant:"
+
+func (c *Controller) validateAgentMemory(content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("%w: content is empty", ErrInvalidAgentMemory)
+	}
+	if limit := c.Options.Config.Apps.MaxMemoryLength; len(content) > limit {
+		return fmt.Errorf("%w: content is longer than %d characters", ErrInvalidAgentMemory, limit)
+	}
+	return nil
+}
+
+func (c *Controller) ListAgentMemories(ctx context.Context, user *types.User, appID string) ([]*types.AgentMemory, error) {
+	return c.Options.Store.ListAgentMemories(ctx, &store.ListAgentMemoriesQuery{
+		AppID:     appID,
+		Owner:     user.ID,
+		OwnerType: user.Type,
+	})
+}
+
+func (c *Controller) CreateAgentMemory(ctx context.Context, user *types.User, appID string, req *types.AgentMemoryRequest) (*types.AgentMemory, error) {
+	if err := c.validateAgentMemory(req.Content); err != nil {
+		return nil, err
+	}
+
+	existing, err := c.ListAgentMemories(ctx, user, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent memories: %w", err)
+	}
+	if limit := c.Options.Config.Apps.MaxMemories; len(existing) >= limit {
+		return nil, fmt.Errorf("%w: the app already has %d memories, delete some first", ErrInvalidAgentMemory, limit)
+	}
+
+	return c.Options.Store.CreateAgentMemory(ctx, &types.AgentMemory{
+		AppID:     appID,
+		Owner:     user.ID,
+		OwnerType: user.Type,
+		Content:   strings.TrimSpace(req.Content),
+	})
+}
+
+func (c *Controller) UpdateAgentMemory(ctx context.Context, user *types.User, appID, id string, req *types.AgentMemoryRequest) (*types.AgentMemory, error) {
+	if err := c.validateAgentMemory(req.Content); err != nil {
+		return nil, err
+	}
+
+	memory, err := c.getOwnAgentMemory(ctx, user, appID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	memory.Content = strings.TrimSpace(req.Content)
+
+	return c.Options.Store.UpdateAgentMemory(ctx, memory)
+}
+
+func (c *Controller) DeleteAgentMemory(ctx context.Context, user *types.User, appID, id string) error {
+	memory, err := c.getOwnAgentMemory(ctx, user, appID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.Options.Store.DeleteAgentMemory(ctx, memory.ID)
+}
+
+// getOwnAgentMemory treats memories of other users or apps as not found
+func (c *Controller) getOwnAgentMemory(ctx context.Context, user *types.User, appID, id string) (*types.AgentMemory, error) {
+	memory, err := c.Options.Store.GetAgentMemory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if memory.AppID != appID || memory.Owner != user.ID || memory.OwnerType != user.Type {
+		return nil, store.ErrNotFound
+	}
+	return memory, nil
+}
+
+// enrichPromptWithMemories adds the user's memories for the app to the end of
+// the system prompt
+func (c *Controller) enrichPromptWithMemories(ctx context.Context, user *types.User, req *openai.ChatCompletionRequest, appID string) error {
+	if appID == "" || user.ID == "" {
+		return nil
+	}
+
+	memories, err := c.ListAgentMemories(ctx, user, appID)
+	if err != nil {
+		return err
+	}
+	if len(memories) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(agentMemoryPromptHeader)
+	for _, memory := range memories {
+		sb.WriteString("\n- ")
+		sb.WriteString(memory.Content)
+	}
+
+	if len(req.Messages) > 0 && req.Messages[0].Role == openai.ChatMessageRoleSystem {
+		req.Messages[0].Content = req.Messages[0].Content + "\n\n" + sb.String()
+		return nil
+	}
+
+	*req = setSystemPrompt(req, sb.String())
+	return nil
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newAgentMemoryTestController(t *testing.T) (*Controller, *store.MockStore) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Apps.MaxMemoryLength = 20
+	cfg.Apps.MaxMemories = 2
+
+	return &Controller{Options: Options{Store: storeMock, Config: cfg}}, storeMock
+}
+
+func TestCreateAgentMemory(t *testing.T) {
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+
+	tests := []struct {
+		name     string
+		content  string
+		existing []*types.AgentMemory
+		wantErr  bool
+	}{
+		{
+			name:    "created",
+			content: "  uses pnpm  ",
+		},
+		{
+			name:    "empty",
+			content: "   ",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			content: strings.Repeat("a", 21),
+			wantErr: true,
+		},
+		{
+			name:     "limit reached",
+			content:  "uses pnpm",
+			existing: []*types.AgentMemory{{ID: "mem_1"}, {ID: "mem_2"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, storeMock := newAgentMemoryTestController(t)
+
+			if strings.TrimSpace(tt.content) != "" && len(tt.content) <= 20 {
+				storeMock.EXPECT().ListAgentMemories(gomock.Any(), &store.ListAgentMemoriesQuery{
+					AppID:     "app_1",
+					Owner:     "user_1",
+					OwnerType: types.OwnerTypeUser,
+				}).Return(tt.existing, nil)
+			}
+			if !tt.wantErr {
+				storeMock.EXPECT().CreateAgentMemory(gomock.Any(), &types.AgentMemory{
+					AppID:     "app_1",
+					Owner:     "user_1",
+					OwnerType: types.OwnerTypeUser,
+					Content:   "uses pnpm",
+				}).DoAndReturn(func(_ context.Context, m *types.AgentMemory) (*types.AgentMemory, error) {
+					return m, nil
+				})
+			}
+
+			_, err := c.CreateAgentMemory(context.Background(), user, "app_1", &types.AgentMemoryRequest{Content: tt.content})
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidAgentMemory)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDeleteAgentMemory_OtherUser(t *testing.T) {
+	c, storeMock := newAgentMemoryTestController(t)
+
+	storeMock.EXPECT().GetAgentMemory(gomock.Any(), "mem_1").Return(&types.AgentMemory{
+		ID:        "mem_1",
+		AppID:     "app_1",
+		Owner:     "user_2",
+		OwnerType: types.OwnerTypeUser,
+	}, nil)
+
+	err := c.DeleteAgentMemory(context.Background(), &types.User{ID: "user_1", Type: types.OwnerTypeUser}, "app_1", "mem_1")
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestEnrichPromptWithMemories(t *testing.T) {
+	memories := []*types.AgentMemory{{Content: "uses pnpm"}, {Content: "tests live next to the code"}}
+	user := &types.User{ID: "user_1", Type: types.OwnerTypeUser}
+
+	t.Run("appended to existing system prompt", func(t *testing.T) {
+		c, storeMock := newAgentMemoryTestController(t)
+		storeMock.EXPECT().ListAgentMemories(gomock.Any(), gomock.Any()).Return(memories, nil)
+
+		req := openai.ChatCompletionRequest{
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "You are a coding agent."},
+				{Role: openai.ChatMessageRoleUser, Content: "hi"},
+			},
+		}
+
+		err := c.enrichPromptWithMemories(context.Background(), user, &req, "app_1")
+		require.NoError(t, err)
+		require.Len(t, req.Messages, 2)
+		require.Equal(t, "You are a coding agent.\n\n"+agentMemoryPromptHeader+"\n- uses pnpm\n- tests live next to the code", req.Messages[0].Content)
+	})
+
+	t.Run("system prompt added", func(t *testing.T) {
+		c, storeMock := newAgentMemoryTestController(t)
+		storeMock.EXPECT().ListAgentMemories(gomock.Any(), gomock.Any()).Return(memories[:1], nil)
+
+		req := openai.ChatCompletionRequest{
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleUser, Content: "hi"},
+			},
+		}
+
+		err := c.enrichPromptWithMemories(context.Background(), user, &req, "app_1")
+		require.NoError(t, err)
+		require.Len(t, req.Messages, 2)
+		require.Equal(t, openai.ChatMessageRoleSystem, req.Messages[0].Role)
+		require.Equal(t, agentMemoryPromptHeader+"\n- uses pnpm", req.Messages[0].Content)
+	})
+
+	t.Run("no app", func(t *testing.T) {
+		c, _ := newAgentMemoryTestController(t)
+
+		req := openai.ChatCompletionRequest{}
+		err := c.enrichPromptWithMemories(context.Background(), user, &req, "")
+		require.NoError(t, err)
+		require.Empty(t, req.Messages)
+	})
+}
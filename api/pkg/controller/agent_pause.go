@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrAgentsPaused is returned instead of running LLM calls or tools while
+// an emergency pause is active for the caller
+var ErrAgentsPaused = errors.New("agents are paused")
+
+// PauseAgents stops all LLM calls and tool execution for the user's sessions, or
+// for every session on the instance if a global pause is requested by an admin
+func (c *Controller) PauseAgents(ctx context.Context, user *types.User, req *types.AgentPauseRequest) (*types.AgentPause, error) {
+	pause := &types.AgentPause{
+		ID:        user.ID,
+		Owner:     user.ID,
+		OwnerType: user.Type,
+		PausedBy:  user.ID,
+		Reason:    req.Reason,
+	}
+
+	if req.Global {
+		if !user.Admin {
+			return nil, fmt.Errorf("only admins can pause agents globally")
+		}
+		pause.ID = types.AgentPauseGlobalID
+		pause.Owner = ""
+		pause.OwnerType = ""
+	}
+
+	created, err := c.Options.Store.CreateAgentPause(ctx, pause)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent pause: %w", err)
+	}
+
+	log.Warn().
+		Str("pause_id", created.ID).
+		Str("paused_by", created.PausedBy).
+		Str("reason", created.Reason).
+		Msg("agents paused")
+
+	return created, nil
+}
+
+// ResumeAgents lifts a pause previously set with PauseAgents
+func (c *Controller) ResumeAgents(ctx context.Context, user *types.User, global bool) error {
+	id := user.ID
+	if global {
+		if !user.Admin {
+			return fmt.Errorf("only admins can resume agents globally")
+		}
+		id = types.AgentPauseGlobalID
+	}
+
+	err := c.Options.Store.DeleteAgentPause(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent pause: %w", err)
+	}
+
+	log.Info().
+		Str("pause_id", id).
+		Str("resumed_by", user.ID).
+		Msg("agents resumed")
+
+	return nil
+}
+
+// GetAgentPauseStatus returns the pauses that apply to the given owner
+func (c *Controller) GetAgentPauseStatus(ctx context.Context, owner string) (*types.AgentPauseStatus, error) {
+	status := &types.AgentPauseStatus{}
+
+	global, err := c.Options.Store.GetAgentPause(ctx, types.AgentPauseGlobalID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	status.Global = global
+
+	userPause, err := c.Options.Store.GetAgentPause(ctx, owner)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	status.User = userPause
+
+	status.Paused = status.Global != nil || status.User != nil
+
+	return status, nil
+}
+
+// CheckAgentsPaused returns an error wrapping ErrAgentsPaused if the owner's
+// agents must not run. The message is shown to the user in the session.
+func (c *Controller) CheckAgentsPaused(ctx context.Context, owner string) error {
+	status, err := c.GetAgentPauseStatus(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("failed to check agent pause status: %w", err)
+	}
+
+	switch {
+	case status.Global != nil:
+		return agentPauseError(status.Global, "by an administrator")
+	case status.User != nil:
+		return agentPauseError(status.User, "for your account")
+	}
+
+	return nil
+}
+
+func agentPauseError(pause *types.AgentPause, scope string) error {
+	if pause.Reason == "" {
+		return fmt.Errorf("%w %s", ErrAgentsPaused, scope)
+	}
+	return fmt.Errorf("%w %s: %s", ErrAgentsPaused, scope, pause.Reason)
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/janitor"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCheckAgentsPaused(t *testing.T) {
+	tests := []struct {
+		name      string
+		owner     string
+		global    *types.AgentPause
+		user      *types.AgentPause
+		wantErr   bool
+		wantInMsg string
+	}{
+		{
+			name:  "not paused",
+			owner: "user_id",
+		},
+		{
+			name:      "global pause",
+			owner:     "user_id",
+			global:    &types.AgentPause{ID: types.AgentPauseGlobalID, Reason: "leaked key"},
+			wantErr:   true,
+			wantInMsg: "by an administrator: leaked key",
+		},
+		{
+			name:      "user pause",
+			owner:     "user_id",
+			user:      &types.AgentPause{ID: "user_id"},
+			wantErr:   true,
+			wantInMsg: "for your account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storeMock := store.NewMockStore(ctrl)
+
+			storeMock.EXPECT().GetAgentPause(gomock.Any(), types.AgentPauseGlobalID).DoAndReturn(
+				func(context.Context, string) (*types.AgentPause, error) {
+					if tt.global == nil {
+						return nil, store.ErrNotFound
+					}
+					return tt.global, nil
+				})
+
+			storeMock.EXPECT().GetAgentPause(gomock.Any(), tt.owner).DoAndReturn(
+				func(context.Context, string) (*types.AgentPause, error) {
+					if tt.user == nil {
+						return nil, store.ErrNotFound
+					}
+					return tt.user, nil
+				})
+
+			c := &Controller{Options: Options{Store: storeMock}}
+
+			err := c.CheckAgentsPaused(context.Background(), tt.owner)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrAgentsPaused)
+			require.Contains(t, err.Error(), tt.wantInMsg)
+		})
+	}
+}
+
+func TestPauseAgents_GlobalRequiresAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	c := &Controller{Options: Options{Store: storeMock}}
+
+	_, err := c.PauseAgents(context.Background(), &types.User{ID: "user_id"}, &types.AgentPauseRequest{Global: true})
+	require.Error(t, err)
+
+	storeMock.EXPECT().CreateAgentPause(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, pause *types.AgentPause) (*types.AgentPause, error) {
+			return pause, nil
+		})
+
+	pause, err := c.PauseAgents(context.Background(), &types.User{ID: "admin_id", Admin: true}, &types.AgentPauseRequest{Global: true, Reason: "incident"})
+	require.NoError(t, err)
+	require.Equal(t, types.AgentPauseGlobalID, pause.ID)
+	require.Equal(t, "admin_id", pause.PausedBy)
+	require.Empty(t, pause.Owner)
+}
+
+func TestUpdateSession_Paused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	c := &Controller{Options: Options{Store: storeMock}}
+
+	storeMock.EXPECT().GetSession(gomock.Any(), "ses_1").Return(&types.Session{ID: "ses_1", Owner: "user_id"}, nil)
+	storeMock.EXPECT().GetAgentPause(gomock.Any(), types.AgentPauseGlobalID).Return(nil, store.ErrNotFound)
+	storeMock.EXPECT().GetAgentPause(gomock.Any(), "user_id").Return(&types.AgentPause{ID: "user_id"}, nil)
+
+	_, err := c.UpdateSession(context.Background(), &types.User{ID: "user_id"}, types.UpdateSessionRequest{
+		SessionID:       "ses_1",
+		UserInteraction: &types.Interaction{Creator: types.CreatorTypeUser},
+	})
+	require.ErrorIs(t, err, ErrAgentsPaused)
+}
+
+func TestSessionRunner_Paused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	ps, err := pubsub.New(t.TempDir())
+	require.NoError(t, err)
+
+	c := &Controller{Options: Options{
+		Store:   storeMock,
+		PubSub:  ps,
+		Janitor: janitor.NewJanitor(config.Janitor{}),
+	}}
+
+	session := &types.Session{
+		ID:    "ses_1",
+		Owner: "user_id",
+		Interactions: []*types.Interaction{
+			{ID: "i1", Creator: types.CreatorTypeUser},
+			{ID: "i2", Creator: types.CreatorTypeAssistant, State: types.InteractionStateWaiting},
+		},
+	}
+
+	storeMock.EXPECT().GetAgentPause(gomock.Any(), types.AgentPauseGlobalID).Return(&types.AgentPause{ID: types.AgentPauseGlobalID, Reason: "incident"}, nil)
+	storeMock.EXPECT().GetAgentPause(gomock.Any(), "user_id").Return(nil, store.ErrNotFound)
+	storeMock.EXPECT().UpdateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, session types.Session) (*types.Session, error) {
+			// The owner sees the pause in the session instead of a reply
+			assistant := session.Interactions[1]
+			require.Equal(t, types.InteractionStateError, assistant.State)
+			require.Contains(t, assistant.Error, "by an administrator: incident")
+			return &session, nil
+		})
+
+	c.SessionRunner(context.Background(), session)
+}
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	// sessions whose last interaction hasn't finished after this long are counted as abandoned
+	sessionAbandonedAfter = time.Hour
+	// number of failing tools reported per bucket
+	topToolFailures = 5
+	// sessions read from the store at a time
+	appAnalyticsPageSize = 500
+)
+
+// GetAppAnalytics aggregates session outcomes, durations, token usage and tool
+// failures for all sessions started from the app in the query range. Sessions
+// are read a page at a time so long ranges don't hold them all in memory.
+func (c *Controller) GetAppAnalytics(ctx context.Context, q *types.AppAnalyticsQuery) (*types.AppAnalytics, error) {
+	storeQuery := &store.ListAppSessionsQuery{
+		AppID: q.AppID,
+		From:  q.From,
+		To:    q.To,
+		Limit: appAnalyticsPageSize,
+	}
+
+	tokens, err := c.Options.Store.SumLLMCallTokensBySession(ctx, storeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum app token usage: %w", err)
+	}
+
+	summary := newAppAnalyticsSummary(q)
+	now := time.Now()
+
+	for {
+		sessions, err := c.Options.Store.ListAppSessions(ctx, storeQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list app sessions: %w", err)
+		}
+
+		for _, session := range sessions {
+			summary.add(session, tokens[session.ID], now)
+		}
+
+		if len(sessions) < appAnalyticsPageSize {
+			return summary.finish(), nil
+		}
+
+		last := sessions[len(sessions)-1]
+		storeQuery.AfterCreated = last.Created
+		storeQuery.AfterID = last.ID
+	}
+}
+
+type sessionOutcome int
+
+const (
+	sessionOutcomeInProgress sessionOutcome = iota
+	sessionOutcomeCompleted
+	sessionOutcomeFailed
+	sessionOutcomeAbandoned
+)
+
+// sessionAccumulator collects per-bucket sums before they are turned into averages
+type sessionAccumulator struct {
+	analytics     *types.SessionAnalytics
+	durationTotal time.Duration
+	durationCount int
+	tokenTotal    int64
+	toolFailures  map[string]int
+}
+
+func newSessionAccumulator(start time.Time) *sessionAccumulator {
+	return &sessionAccumulator{
+		analytics:    &types.SessionAnalytics{Start: start, ToolFailures: []*types.ToolFailureCount{}},
+		toolFailures: map[string]int{},
+	}
+}
+
+func (a *sessionAccumulator) add(session *types.Session, tokens int64, now time.Time) {
+	a.analytics.SessionsStarted++
+	a.tokenTotal += tokens
+
+	outcome, finished := getSessionOutcome(session, now)
+	switch outcome {
+	case sessionOutcomeCompleted:
+		a.analytics.SessionsCompleted++
+	case sessionOutcomeFailed:
+		a.analytics.SessionsFailed++
+	case sessionOutcomeAbandoned:
+		a.analytics.SessionsAbandoned++
+	}
+
+	if !finished.IsZero() && finished.After(session.Created) {
+		a.durationTotal += finished.Sub(session.Created)
+		a.durationCount++
+	}
+
+	if session.ArchivedAt != nil {
+		a.analytics.SessionsArchived++
+	}
+
+	for _, interaction := range session.Interactions {
+		tool := interaction.Metadata["tool_action"]
+		if tool == "" {
+			continue
+		}
+		if interaction.Error != "" || interaction.Metadata["error"] != "" {
+			a.toolFailures[tool]++
+		}
+	}
+}
+
+func (a *sessionAccumulator) finish() *types.SessionAnalytics {
+	result := a.analytics
+
+	ended := result.SessionsCompleted + result.SessionsFailed + result.SessionsAbandoned
+	if ended > 0 {
+		result.SuccessRate = float64(result.SessionsCompleted) / float64(ended)
+	}
+	if a.durationCount > 0 {
+		result.AverageDurationSeconds = a.durationTotal.Seconds() / float64(a.durationCount)
+	}
+	if result.SessionsStarted > 0 {
+		result.AverageTokens = float64(a.tokenTotal) / float64(result.SessionsStarted)
+	}
+
+	for tool, count := range a.toolFailures {
+		result.ToolFailures = append(result.ToolFailures, &types.ToolFailureCount{Tool: tool, Count: count})
+	}
+	sort.Slice(result.ToolFailures, func(i, j int) bool {
+		if result.ToolFailures[i].Count == result.ToolFailures[j].Count {
+			return result.ToolFailures[i].Tool < result.ToolFailures[j].Tool
+		}
+		return result.ToolFailures[i].Count > result.ToolFailures[j].Count
+	})
+	if len(result.ToolFailures) > topToolFailures {
+		result.ToolFailures = result.ToolFailures[:topToolFailures]
+	}
+
+	return result
+}
+
+// appAnalyticsSummary accumulates the totals and per-interval buckets as
+// sessions are added
+type appAnalyticsSummary struct {
+	q       *types.AppAnalyticsQuery
+	total   *sessionAccumulator
+	buckets []*sessionAccumulator
+	byStart map[time.Time]*sessionAccumulator
+}
+
+func newAppAnalyticsSummary(q *types.AppAnalyticsQuery) *appAnalyticsSummary {
+	summary := &appAnalyticsSummary{
+		q:       q,
+		total:   newSessionAccumulator(q.From),
+		byStart: map[time.Time]*sessionAccumulator{},
+	}
+
+	for start := truncateToInterval(q.From, q.Interval); start.Before(q.To); start = nextInterval(start, q.Interval) {
+		bucket := newSessionAccumulator(start)
+		summary.buckets = append(summary.buckets, bucket)
+		summary.byStart[start] = bucket
+	}
+
+	return summary
+}
+
+func (s *appAnalyticsSummary) add(session *types.Session, tokens int64, now time.Time) {
+	s.total.add(session, tokens, now)
+
+	if bucket, ok := s.byStart[truncateToInterval(session.Created, s.q.Interval)]; ok {
+		bucket.add(session, tokens, now)
+	}
+}
+
+func (s *appAnalyticsSummary) finish() *types.AppAnalytics {
+	result := &types.AppAnalytics{
+		AppID:    s.q.AppID,
+		From:     s.q.From,
+		To:       s.q.To,
+		Interval: s.q.Interval,
+		Total:    s.total.finish(),
+		Buckets:  make([]*types.SessionAnalytics, 0, len(s.buckets)),
+	}
+	for _, bucket := range s.buckets {
+		result.Buckets = append(result.Buckets, bucket.finish())
+	}
+
+	return result
+}
+
+// getSessionOutcome classifies the session by its last interaction and returns
+// when it finished (zero if it hasn't)
+func getSessionOutcome(session *types.Session, now time.Time) (sessionOutcome, time.Time) {
+	if len(session.Interactions) == 0 {
+		return sessionOutcomeAbandoned, time.Time{}
+	}
+
+	last := session.Interactions[len(session.Interactions)-1]
+
+	finished := last.Completed
+	if finished.IsZero() {
+		finished = last.Updated
+	}
+
+	switch last.State {
+	case types.InteractionStateComplete:
+		return sessionOutcomeCompleted, finished
+	case types.InteractionStateError:
+		return sessionOutcomeFailed, finished
+	}
+
+	lastActivity := last.Updated
+	if lastActivity.IsZero() {
+		lastActivity = session.Updated
+	}
+	if now.Sub(lastActivity) > sessionAbandonedAfter {
+		return sessionOutcomeAbandoned, time.Time{}
+	}
+
+	return sessionOutcomeInProgress, time.Time{}
+}
+
+func truncateToInterval(t time.Time, interval types.AnalyticsInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch interval {
+	case types.AnalyticsIntervalWeek:
+		// weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case types.AnalyticsIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextInterval(t time.Time, interval types.AnalyticsInterval) time.Time {
+	switch interval {
+	case types.AnalyticsIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case types.AnalyticsIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
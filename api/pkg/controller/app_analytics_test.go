@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSummarizeAppSessions(t *testing.T) {
+	from := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC) // Monday
+	to := from.AddDate(0, 0, 2)
+	now := to.Add(48 * time.Hour)
+
+	session := func(id string, created time.Time, state types.InteractionState, took time.Duration, toolErrors ...string) *types.Session {
+		interactions := []*types.Interaction{
+			{Creator: types.CreatorTypeUser, State: types.InteractionStateComplete},
+		}
+		for _, tool := range toolErrors {
+			interactions = append(interactions, &types.Interaction{
+				Creator:  types.CreatorTypeAssistant,
+				State:    types.InteractionStateComplete,
+				Metadata: map[string]string{"tool_action": tool, "error": "boom"},
+			})
+		}
+		last := &types.Interaction{Creator: types.CreatorTypeAssistant, State: state, Updated: created.Add(took)}
+		if state == types.InteractionStateComplete || state == types.InteractionStateError {
+			last.Completed = created.Add(took)
+		}
+		interactions = append(interactions, last)
+
+		return &types.Session{ID: id, Created: created, Interactions: interactions}
+	}
+
+	sessions := []*types.Session{
+		session("s1", from.Add(time.Hour), types.InteractionStateComplete, 10*time.Second),
+		session("s2", from.Add(2*time.Hour), types.InteractionStateError, 30*time.Second, "getWeather", "getWeather"),
+		session("s3", from.Add(26*time.Hour), types.InteractionStateWaiting, time.Second, "listPets"),
+	}
+
+	archivedAt := now
+	sessions[0].ArchivedAt = &archivedAt
+
+	tokens := map[string]int64{"s1": 100, "s2": 300, "s3": 200}
+
+	summary := newAppAnalyticsSummary(&types.AppAnalyticsQuery{
+		AppID:    "app_id",
+		From:     from,
+		To:       to,
+		Interval: types.AnalyticsIntervalDay,
+	})
+	for _, session := range sessions {
+		summary.add(session, tokens[session.ID], now)
+	}
+	result := summary.finish()
+
+	require.Equal(t, 3, result.Total.SessionsStarted)
+	require.Equal(t, 1, result.Total.SessionsCompleted)
+	require.Equal(t, 1, result.Total.SessionsFailed)
+	require.Equal(t, 1, result.Total.SessionsAbandoned)
+	require.Equal(t, 1, result.Total.SessionsArchived)
+	require.InDelta(t, 1.0/3.0, result.Total.SuccessRate, 0.0001)
+	require.InDelta(t, 20.0, result.Total.AverageDurationSeconds, 0.0001)
+	require.InDelta(t, 200.0, result.Total.AverageTokens, 0.0001)
+	require.Equal(t, []*types.ToolFailureCount{
+		{Tool: "getWeather", Count: 2},
+		{Tool: "listPets", Count: 1},
+	}, result.Total.ToolFailures)
+
+	require.Len(t, result.Buckets, 2)
+	require.Equal(t, from, result.Buckets[0].Start)
+	require.Equal(t, 2, result.Buckets[0].SessionsStarted)
+	require.Equal(t, 1, result.Buckets[1].SessionsStarted)
+	require.Equal(t, 1, result.Buckets[1].SessionsAbandoned)
+}
+
+func TestGetAppAnalytics_Pages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	c := &Controller{Options: Options{Store: storeMock}}
+
+	from := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	q := &types.AppAnalyticsQuery{AppID: "app_id", From: from, To: from.AddDate(0, 0, 1), Interval: types.AnalyticsIntervalDay}
+
+	var sessions []*types.Session
+	for i := 0; i < appAnalyticsPageSize+1; i++ {
+		sessions = append(sessions, &types.Session{ID: fmt.Sprintf("s%d", i), Created: from.Add(time.Duration(i) * time.Second)})
+	}
+	last := sessions[appAnalyticsPageSize-1]
+
+	storeMock.EXPECT().SumLLMCallTokensBySession(gomock.Any(), gomock.Any()).Return(map[string]int64{"s0": 10}, nil)
+	gomock.InOrder(
+		storeMock.EXPECT().ListAppSessions(gomock.Any(), &store.ListAppSessionsQuery{
+			AppID: "app_id",
+			From:  q.From,
+			To:    q.To,
+			Limit: appAnalyticsPageSize,
+		}).Return(sessions[:appAnalyticsPageSize], nil),
+		storeMock.EXPECT().ListAppSessions(gomock.Any(), &store.ListAppSessionsQuery{
+			AppID:        "app_id",
+			From:         q.From,
+			To:           q.To,
+			AfterCreated: last.Created,
+			AfterID:      last.ID,
+			Limit:        appAnalyticsPageSize,
+		}).Return(sessions[appAnalyticsPageSize:], nil),
+	)
+
+	result, err := c.GetAppAnalytics(context.Background(), q)
+	require.NoError(t, err)
+	require.Equal(t, appAnalyticsPageSize+1, result.Total.SessionsStarted)
+	require.Equal(t, appAnalyticsPageSize+1, result.Buckets[0].SessionsStarted)
+}
+
+func TestGetSessionOutcome_InProgress(t *testing.T) {
+	now := time.Now()
+	session := &types.Session{
+		Created: now.Add(-time.Minute),
+		Interactions: []*types.Interaction{
+			{State: types.InteractionStateWaiting, Updated: now.Add(-time.Minute)},
+		},
+	}
+
+	outcome, finished := getSessionOutcome(session, now)
+	require.Equal(t, sessionOutcomeInProgress, outcome)
+	require.True(t, finished.IsZero())
+}
+
+func TestTruncateToInterval(t *testing.T) {
+	ts := time.Date(2024, 6, 6, 15, 4, 5, 0, time.UTC) // Thursday
+
+	require.Equal(t, time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC), truncateToInterval(ts, types.AnalyticsIntervalDay))
+	require.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), truncateToInterval(ts, types.AnalyticsIntervalWeek))
+	require.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), truncateToInterval(ts, types.AnalyticsIntervalMonth))
+}
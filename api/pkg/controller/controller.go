@@ -39,6 +39,8 @@ type Options struct {
 	ProviderManager      manager.ProviderManager
 	DataprepOpenAIClient openai.Client
 	Scheduler            scheduler.Scheduler
+	// Customer managed vector stores, shared with the knowledge reconciler
+	VectorStores *rag.VectorStores
 }
 
 type Controller struct {
@@ -51,6 +53,7 @@ type Controller struct {
 	dataprepOpenAIClient openai.Client
 
 	newRagClient func(settings *types.RAGSettings) rag.RAG
+	vectorStores *rag.VectorStores
 
 	// keep a map of instantiated models so we can ask it about memory
 	// the models package looks after instantiating this for us
@@ -64,6 +67,8 @@ type Controller struct {
 	schedulingDecisions []*types.GlobalSchedulingDecision
 
 	scheduler scheduler.Scheduler
+
+	scratchLocks scratchLocks
 }
 
 func NewController(
@@ -91,6 +96,10 @@ func NewController(
 		return nil, err
 	}
 
+	if options.VectorStores == nil {
+		options.VectorStores = rag.NewVectorStores(options.Store)
+	}
+
 	controller := &Controller{
 		Ctx:                  ctx,
 		Options:              options,
@@ -100,6 +109,7 @@ func NewController(
 		newRagClient: func(settings *types.RAGSettings) rag.RAG {
 			return rag.NewLlamaindex(settings)
 		},
+		vectorStores:        options.VectorStores,
 		activeRunners:       xsync.NewMapOf[string, *types.RunnerState](),
 		schedulingDecisions: []*types.GlobalSchedulingDecision{},
 		scheduler:           options.Scheduler,
@@ -146,5 +156,10 @@ func (c *Controller) run(ctx context.Context) error {
 		log.Error().Msgf("error in controller loop: %s", err.Error())
 		debug.PrintStack()
 	}
+
+	err = c.cleanExpiredSessionScratch(ctx)
+	if err != nil {
+		log.Error().Msgf("error cleaning expired session scratch: %s", err.Error())
+	}
 	return nil
 }
@@ -3,6 +3,9 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"slices"
 	"time"
 
 	"github.com/helixml/helix/api/pkg/store"
@@ -33,6 +36,10 @@ func (c *Controller) CreateAPIKey(ctx context.Context, user *types.User, apiKey
 		return nil, err
 	}
 
+	if err := validateAPIKeyRestrictions(apiKey, time.Now()); err != nil {
+		return nil, err
+	}
+
 	apiKey.Key = key
 	apiKey.Owner = user.ID
 	apiKey.OwnerType = user.Type
@@ -40,6 +47,29 @@ func (c *Controller) CreateAPIKey(ctx context.Context, user *types.User, apiKey
 	return c.Options.Store.CreateAPIKey(ctx, apiKey)
 }
 
+func validateAPIKeyRestrictions(apiKey *types.APIKey, now time.Time) error {
+	for _, scope := range apiKey.Scopes {
+		if !slices.Contains(types.APIKeyScopes, types.APIKeyScope(scope)) {
+			return fmt.Errorf("unknown API key scope '%s'", scope)
+		}
+	}
+
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return fmt.Errorf("API key expiry must be in the future")
+	}
+
+	for _, entry := range apiKey.IPAllowlist {
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return fmt.Errorf("invalid IP allowlist entry '%s', expected an IP or CIDR range", entry)
+		}
+	}
+
+	return nil
+}
+
 func (c *Controller) GetAPIKeys(ctx context.Context, user *types.User) ([]*types.APIKey, error) {
 	apiKeys, err := c.Options.Store.ListAPIKeys(ctx, &store.ListAPIKeysQuery{
 		Owner:     user.ID,
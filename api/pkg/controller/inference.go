@@ -34,6 +34,10 @@ type ChatCompletionOptions struct {
 // Runs the OpenAI with tools/app configuration and returns the response.
 // Returns the updated request because the controller mutates it when doing e.g. tools calls and RAG
 func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req openai.ChatCompletionRequest, opts *ChatCompletionOptions) (*openai.ChatCompletionResponse, *openai.ChatCompletionRequest, error) {
+	if err := c.CheckAgentsPaused(ctx, user.ID); err != nil {
+		return nil, nil, err
+	}
+
 	assistant, err := c.loadAssistant(ctx, user, opts)
 	if err != nil {
 		log.Info().Msg("no assistant found")
@@ -55,6 +59,11 @@ func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req o
 
 	req = setSystemPrompt(&req, assistant.SystemPrompt)
 
+	err = c.enrichPromptWithMemories(ctx, user, &req, opts.AppID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load agent memories: %w", err)
+	}
+
 	if assistant.Model != "" {
 		req.Model = assistant.Model
 
@@ -98,6 +107,10 @@ func (c *Controller) ChatCompletion(ctx context.Context, user *types.User, req o
 func (c *Controller) ChatCompletionStream(ctx context.Context, user *types.User, req openai.ChatCompletionRequest, opts *ChatCompletionOptions) (*openai.ChatCompletionStream, *openai.ChatCompletionRequest, error) {
 	req.Stream = true
 
+	if err := c.CheckAgentsPaused(ctx, user.ID); err != nil {
+		return nil, nil, err
+	}
+
 	assistant, err := c.loadAssistant(ctx, user, opts)
 	if err != nil {
 		log.Info().Msg("no assistant found")
@@ -119,6 +132,11 @@ func (c *Controller) ChatCompletionStream(ctx context.Context, user *types.User,
 
 	req = setSystemPrompt(&req, assistant.SystemPrompt)
 
+	err = c.enrichPromptWithMemories(ctx, user, &req, opts.AppID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load agent memories: %w", err)
+	}
+
 	if assistant.Model != "" {
 		req.Model = assistant.Model
 
@@ -688,7 +706,16 @@ func setSystemPrompt(req *openai.ChatCompletionRequest, systemPrompt string) ope
 	return *req
 }
 
-func (c *Controller) GetRagClient(_ context.Context, knowledge *types.Knowledge) (rag.RAG, error) {
+// ReleaseRagClient closes the vector store client of deleted knowledge
+func (c *Controller) ReleaseRagClient(knowledgeID string) {
+	c.vectorStores.Remove(knowledgeID)
+}
+
+func (c *Controller) GetRagClient(ctx context.Context, knowledge *types.Knowledge) (rag.RAG, error) {
+	if knowledge.RAGSettings.VectorStore.Provider != "" {
+		return c.vectorStores.Get(ctx, knowledge)
+	}
+
 	if knowledge.RAGSettings.IndexURL != "" && knowledge.RAGSettings.QueryURL != "" {
 		return rag.NewLlamaindex(&knowledge.RAGSettings), nil
 	}
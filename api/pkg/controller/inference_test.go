@@ -56,6 +56,9 @@ func (suite *ControllerSuite) SetupTest() {
 
 	suite.providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(suite.openAiClient, nil).AnyTimes()
 
+	suite.store.EXPECT().GetAgentPause(gomock.Any(), gomock.Any()).Return(nil, store.ErrNotFound).AnyTimes()
+	suite.store.EXPECT().ListAgentMemories(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
 	filestoreMock := filestore.NewMockFileStore(ctrl)
 	extractorMock := extract.NewMockExtractor(ctrl)
 	suite.rag = rag.NewMockRAG(ctrl)
@@ -32,12 +32,13 @@ type Reconciler struct {
 	httpClient   *http.Client
 	ragClient    rag.RAG                                   // Default server RAG client
 	newRagClient func(settings *types.RAGSettings) rag.RAG // Custom RAG server client constructor
+	vectorStores *rag.VectorStores                         // Customer managed vector stores
 	newCrawler   func(k *types.Knowledge) (crawler.Crawler, error)
 	cron         gocron.Scheduler
 	wg           sync.WaitGroup
 }
 
-func New(config *config.ServerConfig, store store.Store, filestore filestore.FileStore, extractor extract.Extractor, ragClient rag.RAG, b *browser.Browser) (*Reconciler, error) {
+func New(config *config.ServerConfig, store store.Store, filestore filestore.FileStore, extractor extract.Extractor, ragClient rag.RAG, vectorStores *rag.VectorStores, b *browser.Browser) (*Reconciler, error) {
 	s, err := gocron.NewScheduler()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scheduler: %w", err)
@@ -54,6 +55,7 @@ func New(config *config.ServerConfig, store store.Store, filestore filestore.Fil
 		newRagClient: func(settings *types.RAGSettings) rag.RAG {
 			return rag.NewLlamaindex(settings)
 		},
+		vectorStores: vectorStores,
 		newCrawler: func(k *types.Knowledge) (crawler.Crawler, error) {
 			return crawler.NewCrawler(b, k)
 		},
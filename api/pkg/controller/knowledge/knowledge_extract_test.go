@@ -55,7 +55,7 @@ func (suite *ExtractorSuite) SetupTest() {
 
 	var err error
 
-	suite.reconciler, err = New(suite.cfg, suite.store, suite.filestore, suite.extractor, suite.rag, b)
+	suite.reconciler, err = New(suite.cfg, suite.store, suite.filestore, suite.extractor, suite.rag, rag.NewVectorStores(suite.store), b)
 	suite.Require().NoError(err)
 	suite.reconciler.newRagClient = func(_ *types.RAGSettings) rag.RAG {
 		return suite.rag
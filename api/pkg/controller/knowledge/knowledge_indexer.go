@@ -245,9 +245,12 @@ func (r *Reconciler) deleteOldVersions(ctx context.Context, k *types.Knowledge)
 // deleteKnowledgeVersion deletes the knowledge data from the vector DB and the version record from the
 // postgres database
 func (r *Reconciler) deleteKnowledgeVersion(ctx context.Context, k *types.Knowledge, v *types.KnowledgeVersion) error {
-	ragClient := r.getRagClient(k)
+	ragClient, err := r.getRagClient(ctx, k)
+	if err != nil {
+		return fmt.Errorf("failed to get rag client, error: %w", err)
+	}
 
-	err := ragClient.Delete(ctx, &types.DeleteIndexRequest{
+	err = ragClient.Delete(ctx, &types.DeleteIndexRequest{
 		DataEntityID: v.GetDataEntityID(),
 	})
 	if err != nil {
@@ -270,7 +273,17 @@ func getSize(data []*indexerData) int64 {
 	return size
 }
 
-func (r *Reconciler) getRagClient(k *types.Knowledge) rag.RAG {
+func (r *Reconciler) getRagClient(ctx context.Context, k *types.Knowledge) (rag.RAG, error) {
+	if k.RAGSettings.VectorStore.Provider != "" {
+		log.Info().
+			Str("knowledge_id", k.ID).
+			Str("knowledge_name", k.Name).
+			Str("provider", string(k.RAGSettings.VectorStore.Provider)).
+			Msg("using customer managed vector store")
+
+		return r.vectorStores.Get(ctx, k)
+	}
+
 	if k.RAGSettings.IndexURL != "" && k.RAGSettings.QueryURL != "" {
 		log.Info().
 			Str("knowledge_id", k.ID).
@@ -279,9 +292,9 @@ func (r *Reconciler) getRagClient(k *types.Knowledge) rag.RAG {
 			Str("query_url", k.RAGSettings.QueryURL).
 			Msg("using custom RAG server")
 
-		return r.newRagClient(&k.RAGSettings)
+		return r.newRagClient(&k.RAGSettings), nil
 	}
-	return r.ragClient
+	return r.ragClient, nil
 }
 
 func (r *Reconciler) indexData(ctx context.Context, k *types.Knowledge, version string, data []*indexerData) error {
@@ -292,7 +305,10 @@ func (r *Reconciler) indexData(ctx context.Context, k *types.Knowledge, version
 }
 
 func (r *Reconciler) indexDataDirectly(ctx context.Context, k *types.Knowledge, version string, data []*indexerData) error {
-	ragClient := r.getRagClient(k)
+	ragClient, err := r.getRagClient(ctx, k)
+	if err != nil {
+		return fmt.Errorf("failed to get rag client, error: %w", err)
+	}
 
 	log.Info().
 		Str("knowledge_id", k.ID).
@@ -358,7 +374,7 @@ func (r *Reconciler) indexDataDirectly(ctx context.Context, k *types.Knowledge,
 		})
 	}
 
-	err := pool.Wait()
+	err = pool.Wait()
 	if err != nil {
 		return fmt.Errorf("failed to index data, error: %w", err)
 	}
@@ -380,7 +396,10 @@ func (r *Reconciler) indexDataWithChunking(ctx context.Context, k *types.Knowled
 		return fmt.Errorf("failed to split data, error: %w", err)
 	}
 
-	ragClient := r.getRagClient(k)
+	ragClient, err := r.getRagClient(ctx, k)
+	if err != nil {
+		return fmt.Errorf("failed to get rag client, error: %w", err)
+	}
 
 	log.Info().
 		Str("knowledge_id", k.ID).
@@ -14,7 +14,7 @@ import (
 )
 
 // TODO: remove
-func (c *Controller) ShiftSessionQueue(_ context.Context, filter types.SessionFilter, runnerID string) (*types.Session, error) {
+func (c *Controller) ShiftSessionQueue(ctx context.Context, filter types.SessionFilter, runnerID string) (*types.Session, error) {
 	// Default to requesting warm work
 	newWorkOnly := false
 
@@ -33,9 +33,20 @@ func (c *Controller) ShiftSessionQueue(_ context.Context, filter types.SessionFi
 		return nil, nil
 	}
 
-	c.addSchedulingDecision(filter, runnerID, req.Session())
+	// Work queued before the agents were paused is dropped rather than
+	// handed to the runner, the owner sees why in the session
+	session := req.Session()
+	if err := c.CheckAgentsPaused(ctx, session.Owner); err != nil {
+		if releaseErr := c.scheduler.Release(session.ID); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("session_id", session.ID).Msg("failed to release paused session")
+		}
+		c.ErrorSession(ctx, session, err)
+		return nil, nil
+	}
+
+	c.addSchedulingDecision(filter, runnerID, session)
 	log.Info().Str("runnerID", runnerID).Interface("filter", filter).Interface("req", req).Msgf("🟠 helix_openai_server GetNextLLMInferenceRequest END")
-	return req.Session(), nil
+	return session, nil
 }
 
 // TODO: remove
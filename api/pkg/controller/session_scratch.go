@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+var (
+	ErrScratchKeyInvalid    = errors.New("invalid scratch key")
+	ErrScratchKeyConflict   = errors.New("scratch key is a folder of, or inside, an existing object")
+	ErrScratchObjectTooBig  = errors.New("scratch object exceeds the maximum object size")
+	ErrScratchQuotaExceeded = errors.New("session scratch quota exceeded")
+)
+
+const maxScratchKeyLength = 256
+
+var scratchKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._\-/]+$`)
+
+// validateScratchKey only allows relative, already clean paths so that a key
+// can never escape the session's scratch folder
+func validateScratchKey(key string) error {
+	if key == "" || len(key) > maxScratchKeyLength || !scratchKeyPattern.MatchString(key) {
+		return ErrScratchKeyInvalid
+	}
+	if path.IsAbs(key) || path.Clean(key) != key || key == "." || key == ".." || strings.HasPrefix(key, "../") {
+		return ErrScratchKeyInvalid
+	}
+	return nil
+}
+
+// scratchKeysConflict reports whether one key is a path prefix of the other,
+// e.g. "a" and "a/b" can't both be files
+func scratchKeysConflict(a, b string) bool {
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// scratchLocks serializes the writes of each session on this replica so that
+// only one of them at a time waits for the session's lock in the database
+type scratchLocks struct {
+	mu    sync.Mutex
+	locks map[string]*scratchLock
+}
+
+type scratchLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock returns the function to unlock the session, locks are dropped once no
+// write is waiting on them
+func (l *scratchLocks) lock(sessionID string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*scratchLock)
+	}
+	sl, ok := l.locks[sessionID]
+	if !ok {
+		sl = &scratchLock{}
+		l.locks[sessionID] = sl
+	}
+	sl.refs++
+	l.mu.Unlock()
+
+	sl.Lock()
+
+	return func() {
+		sl.Unlock()
+
+		l.mu.Lock()
+		sl.refs--
+		if sl.refs == 0 {
+			delete(l.locks, sessionID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func GetSessionScratchFolder(sessionID string) string {
+	return filepath.Join(GetSessionFolder(sessionID), "scratch")
+}
+
+func (c *Controller) getSessionScratchPath(session *types.Session, key string) (string, error) {
+	return c.GetFilestoreUserPath(types.OwnerContext{
+		Owner:     session.Owner,
+		OwnerType: session.OwnerType,
+	}, filepath.Join(GetSessionScratchFolder(session.ID), key))
+}
+
+// PutSessionScratchObject writes (or overwrites) a scratch object, enforcing the
+// per-object and per-session size limits. Every write extends the object's expiry.
+func (c *Controller) PutSessionScratchObject(ctx context.Context, session *types.Session, key, contentType string, r io.Reader) (*types.SessionScratchObject, error) {
+	if err := validateScratchKey(key); err != nil {
+		return nil, err
+	}
+
+	cfg := c.Options.Config.SessionScratch
+
+	// read one byte past the limit so we can tell if the object is too big
+	// without buffering an unbounded body
+	data, err := io.ReadAll(io.LimitReader(r, cfg.MaxObjectSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scratch object: %w", err)
+	}
+	size := int64(len(data))
+	if size > cfg.MaxObjectSize {
+		return nil, ErrScratchObjectTooBig
+	}
+
+	// concurrent writes, on any replica, could otherwise all pass the quota
+	// check before any of them is recorded
+	unlock := c.scratchLocks.lock(session.ID)
+	defer unlock()
+
+	release, err := c.Options.Store.AcquireLock(ctx, "session-scratch:"+session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session scratch: %w", err)
+	}
+	defer release()
+
+	existing, err := c.Options.Store.ListSessionScratchObjects(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scratch objects: %w", err)
+	}
+	now := time.Now()
+	var used int64
+	for _, obj := range existing {
+		if now.After(obj.Expires) {
+			// expired but not cleaned up yet, it doesn't count towards the quota
+			// and is removed now if it is in the way of the new key
+			if scratchKeysConflict(obj.Key, key) {
+				if err := c.deleteSessionScratchObject(ctx, obj.Owner, obj.OwnerType, obj.SessionID, obj.Key); err != nil {
+					return nil, fmt.Errorf("failed to delete expired scratch object: %w", err)
+				}
+			}
+			continue
+		}
+		if scratchKeysConflict(obj.Key, key) {
+			return nil, ErrScratchKeyConflict
+		}
+		// an overwrite replaces the old object so it doesn't count towards the quota
+		if obj.Key != key {
+			used += obj.Size
+		}
+	}
+	if used+size > cfg.MaxSessionSize {
+		return nil, ErrScratchQuotaExceeded
+	}
+
+	filePath, err := c.getSessionScratchPath(session, key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Options.Filestore.WriteFile(ctx, filePath, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write scratch object: %w", err)
+	}
+
+	return c.Options.Store.CreateSessionScratchObject(ctx, &types.SessionScratchObject{
+		SessionID:   session.ID,
+		Key:         key,
+		Owner:       session.Owner,
+		OwnerType:   session.OwnerType,
+		ContentType: contentType,
+		Size:        size,
+		Expires:     now.Add(cfg.TTL),
+	})
+}
+
+// GetSessionScratchObject returns the object metadata and its content, the caller
+// must close the reader
+func (c *Controller) GetSessionScratchObject(ctx context.Context, session *types.Session, key string) (*types.SessionScratchObject, io.ReadCloser, error) {
+	if err := validateScratchKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := c.Options.Store.GetSessionScratchObject(ctx, session.ID, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(obj.Expires) {
+		// expired but not cleaned up yet
+		return nil, nil, store.ErrNotFound
+	}
+
+	filePath, err := c.getSessionScratchPath(session, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := c.Options.Filestore.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open scratch object: %w", err)
+	}
+	return obj, r, nil
+}
+
+func (c *Controller) ListSessionScratchObjects(ctx context.Context, session *types.Session) ([]*types.SessionScratchObject, error) {
+	objs, err := c.Options.Store.ListSessionScratchObjects(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*types.SessionScratchObject, 0, len(objs))
+	for _, obj := range objs {
+		if now.Before(obj.Expires) {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+func (c *Controller) DeleteSessionScratchObject(ctx context.Context, session *types.Session, key string) error {
+	if err := validateScratchKey(key); err != nil {
+		return err
+	}
+	return c.deleteSessionScratchObject(ctx, session.Owner, session.OwnerType, session.ID, key)
+}
+
+// DeleteSessionScratch removes every scratch object of the session, called when
+// the session is deleted
+func (c *Controller) DeleteSessionScratch(ctx context.Context, session *types.Session) error {
+	objs, err := c.Options.Store.ListSessionScratchObjects(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list scratch objects: %w", err)
+	}
+
+	for _, obj := range objs {
+		if err := c.deleteSessionScratchObject(ctx, obj.Owner, obj.OwnerType, obj.SessionID, obj.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) cleanExpiredSessionScratch(ctx context.Context) error {
+	objs, err := c.Options.Store.ListExpiredSessionScratchObjects(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to list expired scratch objects: %w", err)
+	}
+
+	for _, obj := range objs {
+		if err := c.deleteSessionScratchObject(ctx, obj.Owner, obj.OwnerType, obj.SessionID, obj.Key); err != nil {
+			log.Warn().Err(err).
+				Str("session_id", obj.SessionID).
+				Str("key", obj.Key).
+				Msg("failed to delete expired scratch object")
+		}
+	}
+	return nil
+}
+
+func (c *Controller) deleteSessionScratchObject(ctx context.Context, owner string, ownerType types.OwnerType, sessionID, key string) error {
+	filePath, err := c.getSessionScratchPath(&types.Session{ID: sessionID, Owner: owner, OwnerType: ownerType}, key)
+	if err != nil {
+		return err
+	}
+
+	// the metadata is removed even if the file is already gone
+	if err := c.Options.Filestore.Delete(ctx, filePath); err != nil {
+		log.Debug().Err(err).Str("path", filePath).Msg("failed to delete scratch file")
+	}
+
+	return c.Options.Store.DeleteSessionScratchObject(ctx, sessionID, key)
+}
@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidateScratchKey(t *testing.T) {
+	valid := []string{"report.md", "screenshots/step-1.png", "a_b/c.d/e"}
+	for _, key := range valid {
+		require.NoError(t, validateScratchKey(key), key)
+	}
+
+	invalid := []string{"", "/etc/passwd", "..", "../other", "a/../../b", "a//b", "a/", "./a", "has space", strings.Repeat("a", maxScratchKeyLength+1)}
+	for _, key := range invalid {
+		require.ErrorIs(t, validateScratchKey(key), ErrScratchKeyInvalid, key)
+	}
+}
+
+func newScratchTestController(t *testing.T) (*Controller, *store.MockStore, *filestore.MockFileStore) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+	cfg.SessionScratch = config.SessionScratch{
+		MaxObjectSize:  10,
+		MaxSessionSize: 15,
+		TTL:            time.Hour,
+	}
+
+	// the database lock is taken for every write that fits the object size
+	storeMock.EXPECT().AcquireLock(gomock.Any(), gomock.Any()).Return(func() {}, nil).AnyTimes()
+
+	return &Controller{Options: Options{Store: storeMock, Filestore: fsMock, Config: cfg}}, storeMock, fsMock
+}
+
+func TestPutSessionScratchObject(t *testing.T) {
+	session := &types.Session{ID: "ses_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+	expires := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		key      string
+		content  string
+		existing []*types.SessionScratchObject
+		wantErr  error
+	}{
+		{
+			name:    "new object",
+			key:     "diff.patch",
+			content: "hello",
+		},
+		{
+			name:    "object too big",
+			key:     "diff.patch",
+			content: "01234567890",
+			wantErr: ErrScratchObjectTooBig,
+		},
+		{
+			name:     "quota exceeded",
+			key:      "b",
+			content:  "123456",
+			existing: []*types.SessionScratchObject{{Key: "a", Size: 10, Expires: expires}},
+			wantErr:  ErrScratchQuotaExceeded,
+		},
+		{
+			name:     "overwrite does not count the replaced object",
+			key:      "a",
+			content:  "1234567890",
+			existing: []*types.SessionScratchObject{{Key: "a", Size: 10, Expires: expires}, {Key: "b", Size: 5, Expires: expires}},
+		},
+		{
+			name:     "expired objects don't count towards the quota",
+			key:      "b",
+			content:  "123456",
+			existing: []*types.SessionScratchObject{{Key: "a", Size: 10, Expires: time.Now().Add(-time.Minute)}},
+		},
+		{
+			name:     "key inside an existing object",
+			key:      "a/b",
+			content:  "1",
+			existing: []*types.SessionScratchObject{{Key: "a", Size: 1, Expires: expires}},
+			wantErr:  ErrScratchKeyConflict,
+		},
+		{
+			name:     "key is a folder of an existing object",
+			key:      "a",
+			content:  "1",
+			existing: []*types.SessionScratchObject{{Key: "a/b", Size: 1, Expires: expires}},
+			wantErr:  ErrScratchKeyConflict,
+		},
+		{
+			name:     "keys sharing a name prefix don't conflict",
+			key:      "ab",
+			content:  "1",
+			existing: []*types.SessionScratchObject{{Key: "a", Size: 1, Expires: expires}, {Key: "a.b/c", Size: 1, Expires: expires}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, storeMock, fsMock := newScratchTestController(t)
+
+			// the size is checked before the existing objects are listed
+			if tt.wantErr != ErrScratchObjectTooBig {
+				storeMock.EXPECT().ListSessionScratchObjects(gomock.Any(), "ses_1").Return(tt.existing, nil)
+			}
+
+			if tt.wantErr == nil {
+				fsMock.EXPECT().WriteFile(gomock.Any(), "dev/users/user_1/sessions/ses_1/scratch/"+tt.key, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, r io.Reader) (filestore.Item, error) {
+						data, err := io.ReadAll(r)
+						require.NoError(t, err)
+						require.Equal(t, tt.content, string(data))
+						return filestore.Item{}, nil
+					})
+				storeMock.EXPECT().CreateSessionScratchObject(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error) {
+						require.Equal(t, "ses_1", obj.SessionID)
+						require.Equal(t, "user_1", obj.Owner)
+						require.Equal(t, int64(len(tt.content)), obj.Size)
+						require.WithinDuration(t, time.Now().Add(time.Hour), obj.Expires, time.Minute)
+						return obj, nil
+					})
+			}
+
+			obj, err := c.PutSessionScratchObject(context.Background(), session, tt.key, "text/plain", strings.NewReader(tt.content))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.key, obj.Key)
+		})
+	}
+}
+
+func TestPutSessionScratchObject_RemovesExpiredConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+	cfg.SessionScratch = config.SessionScratch{MaxObjectSize: 10, MaxSessionSize: 15, TTL: time.Hour}
+	c := &Controller{Options: Options{Store: storeMock, Filestore: fsMock, Config: cfg}}
+
+	session := &types.Session{ID: "ses_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+
+	released := false
+	gomock.InOrder(
+		storeMock.EXPECT().AcquireLock(gomock.Any(), "session-scratch:ses_1").Return(func() { released = true }, nil),
+		storeMock.EXPECT().ListSessionScratchObjects(gomock.Any(), "ses_1").Return([]*types.SessionScratchObject{
+			{SessionID: "ses_1", Key: "a", Owner: "user_1", OwnerType: types.OwnerTypeUser, Size: 1, Expires: time.Now().Add(-time.Minute)},
+		}, nil),
+		fsMock.EXPECT().Delete(gomock.Any(), "dev/users/user_1/sessions/ses_1/scratch/a").Return(nil),
+		storeMock.EXPECT().DeleteSessionScratchObject(gomock.Any(), "ses_1", "a").Return(nil),
+		fsMock.EXPECT().WriteFile(gomock.Any(), "dev/users/user_1/sessions/ses_1/scratch/a/b", gomock.Any()).Return(filestore.Item{}, nil),
+		storeMock.EXPECT().CreateSessionScratchObject(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error) {
+				require.False(t, released, "object must be recorded while the lock is held")
+				return obj, nil
+			}),
+	)
+
+	_, err := c.PutSessionScratchObject(context.Background(), session, "a/b", "text/plain", strings.NewReader("1"))
+	require.NoError(t, err)
+	require.True(t, released)
+}
+
+func TestListSessionScratchObjects_HidesExpired(t *testing.T) {
+	c, storeMock, _ := newScratchTestController(t)
+
+	storeMock.EXPECT().ListSessionScratchObjects(gomock.Any(), "ses_1").Return([]*types.SessionScratchObject{
+		{Key: "fresh", Expires: time.Now().Add(time.Minute)},
+		{Key: "stale", Expires: time.Now().Add(-time.Minute)},
+	}, nil)
+
+	objs, err := c.ListSessionScratchObjects(context.Background(), &types.Session{ID: "ses_1"})
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	require.Equal(t, "fresh", objs[0].Key)
+}
+
+func TestCleanExpiredSessionScratch(t *testing.T) {
+	c, storeMock, fsMock := newScratchTestController(t)
+
+	storeMock.EXPECT().ListExpiredSessionScratchObjects(gomock.Any(), gomock.Any()).Return([]*types.SessionScratchObject{
+		{SessionID: "ses_1", Key: "a.png", Owner: "user_1", OwnerType: types.OwnerTypeUser},
+	}, nil)
+	fsMock.EXPECT().Delete(gomock.Any(), "dev/users/user_1/sessions/ses_1/scratch/a.png").Return(nil)
+	storeMock.EXPECT().DeleteSessionScratchObject(gomock.Any(), "ses_1", "a.png").Return(nil)
+
+	require.NoError(t, c.cleanExpiredSessionScratch(context.Background()))
+}
+
+func TestPutSessionScratchObject_ConcurrentWritesRespectQuota(t *testing.T) {
+	c, storeMock, fsMock := newScratchTestController(t)
+	session := &types.Session{ID: "ses_1", Owner: "user_1", OwnerType: types.OwnerTypeUser}
+
+	// the store sees the objects created by earlier writes
+	var (
+		mu      sync.Mutex
+		objects []*types.SessionScratchObject
+	)
+	storeMock.EXPECT().ListSessionScratchObjects(gomock.Any(), "ses_1").DoAndReturn(
+		func(_ context.Context, _ string) ([]*types.SessionScratchObject, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]*types.SessionScratchObject{}, objects...), nil
+		}).AnyTimes()
+	storeMock.EXPECT().CreateSessionScratchObject(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			objects = append(objects, obj)
+			return obj, nil
+		}).AnyTimes()
+	fsMock.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(filestore.Item{}, nil).AnyTimes()
+
+	// each write fits the 15 byte quota on its own, only one of them fits
+	// together with the others
+	const writers = 5
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := c.PutSessionScratchObject(context.Background(), session, fmt.Sprintf("obj-%d", i), "text/plain", strings.NewReader("0123456789"))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrScratchQuotaExceeded)
+	}
+	require.Equal(t, 1, succeeded)
+	require.Len(t, objects, 1)
+	require.Empty(t, c.scratchLocks.locks)
+}
@@ -30,6 +30,10 @@ import (
 const DEBUG = true
 
 func (c *Controller) StartSession(ctx context.Context, user *types.User, req types.InternalSessionRequest) (*types.Session, error) {
+	if err := c.CheckAgentsPaused(ctx, req.Owner); err != nil {
+		return nil, err
+	}
+
 	assistantInteraction := &types.Interaction{
 		ID:             system.GenerateUUID(),
 		Created:        time.Now(),
@@ -196,6 +200,10 @@ func (c *Controller) UpdateSession(ctx context.Context, user *types.User, req ty
 		return nil, fmt.Errorf("failed to get session %s: %w", req.SessionID, err)
 	}
 
+	if err := c.CheckAgentsPaused(ctx, session.Owner); err != nil {
+		return nil, err
+	}
+
 	assistantInteraction := &types.Interaction{
 		ID:       system.GenerateUUID(),
 		Created:  time.Now(),
@@ -375,6 +383,12 @@ func (c *Controller) AddDocumentsToInteraction(ctx context.Context, session *typ
 // the idempotent function to "run" the session
 // it should work out what this means - i.e. have we prepared the data yet?
 func (c *Controller) SessionRunner(ctx context.Context, sessionData *types.Session) {
+	// The pause may have been set since the request was accepted
+	if err := c.CheckAgentsPaused(ctx, sessionData.Owner); err != nil {
+		c.ErrorSession(ctx, sessionData, err)
+		return
+	}
+
 	// Wait for that to complete before adding to the queue
 	// the model can be adding subsequent child sessions to the queue
 	// e.g. in the case of text fine tuning data prep - we need an LLM to convert
@@ -502,6 +516,7 @@ func (c *Controller) PrepareSession(ctx context.Context, session *types.Session)
 			// a chance to decide what to do
 			if session.Metadata.ManuallyReviewQuestions {
 				if convertedTextDocuments > 0 || questionChunksGenerated > 0 {
+					c.notifyApprovalRequired(ctx, session)
 					return nil, nil
 				}
 			}
@@ -513,6 +528,7 @@ func (c *Controller) PrepareSession(ctx context.Context, session *types.Session)
 				return nil, err
 			}
 			if qaPairErrorCount > 0 {
+				c.notifyApprovalRequired(ctx, session)
 				return nil, nil
 			}
 
@@ -886,6 +902,9 @@ func (c *Controller) HandleRunnerResponse(ctx context.Context, taskResponse *typ
 		return nil, fmt.Errorf("session not found: %s", taskResponse.SessionID)
 	}
 
+	// finetunes notify on their own when they complete
+	inference := session.Mode == types.SessionModeInference
+
 	session, err = data.UpdateAssistantInteraction(session, func(targetInteraction *types.Interaction) (*types.Interaction, error) {
 		// mark the interaction as complete if we are a fully finished response
 		if taskResponse.Type == types.WorkerTaskResponseTypeResult {
@@ -983,9 +1002,45 @@ func (c *Controller) HandleRunnerResponse(ctx context.Context, taskResponse *typ
 		}
 	}
 
+	if inference && taskResponse.Type == types.WorkerTaskResponseTypeResult && taskResponse.Error == "" {
+		c.NotifySessionComplete(ctx, session)
+	}
+
 	return taskResponse, nil
 }
 
+// notifyApprovalRequired lets the session owner know that the session is
+// waiting for them to review the prepared data before finetuning continues
+func (c *Controller) notifyApprovalRequired(ctx context.Context, session *types.Session) {
+	if c.Options.Notifier == nil {
+		return
+	}
+
+	err := c.Options.Notifier.Notify(ctx, &notification.Notification{
+		Event:   notification.EventApprovalRequired,
+		Session: session,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("error notifying approval required: %s", err.Error())
+	}
+}
+
+// NotifySessionComplete lets the session owner know that a response is ready.
+// The response has already been written so failures are only logged.
+func (c *Controller) NotifySessionComplete(ctx context.Context, session *types.Session) {
+	if c.Options.Notifier == nil {
+		return
+	}
+
+	err := c.Options.Notifier.Notify(ctx, &notification.Notification{
+		Event:   notification.EventSessionComplete,
+		Session: session,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("error notifying session completed: %s", err.Error())
+	}
+}
+
 type CloneUntilInteractionRequest struct {
 	InteractionID string
 	Mode          types.CloneInteractionMode
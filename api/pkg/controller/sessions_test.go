@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/janitor"
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type recordingNotifier struct {
+	events []notification.Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *notification.Notification) error {
+	n.events = append(n.events, notification.Event)
+	return nil
+}
+
+func TestHandleRunnerResponse_NotifiesSessionComplete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		response *types.RunnerTaskResponse
+		expected []notification.Event
+	}{
+		{
+			name:     "result",
+			response: &types.RunnerTaskResponse{Type: types.WorkerTaskResponseTypeResult, Message: "done"},
+			expected: []notification.Event{notification.EventSessionComplete},
+		},
+		{
+			name:     "stream chunk",
+			response: &types.RunnerTaskResponse{Type: types.WorkerTaskResponseTypeStream, Message: "do"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storeMock := store.NewMockStore(ctrl)
+
+			ps, err := pubsub.New(t.TempDir())
+			require.NoError(t, err)
+
+			notifier := &recordingNotifier{}
+
+			c := &Controller{
+				Options: Options{
+					Store:    storeMock,
+					PubSub:   ps,
+					Janitor:  janitor.NewJanitor(config.Janitor{}),
+					Notifier: notifier,
+				},
+				scheduler: scheduler.NewScheduler(ctx, &config.ServerConfig{}, nil),
+			}
+
+			storeMock.EXPECT().GetSession(gomock.Any(), "ses_1").Return(&types.Session{
+				ID:    "ses_1",
+				Owner: "user_id",
+				Mode:  types.SessionModeInference,
+				Interactions: []*types.Interaction{
+					{ID: "i1", Creator: types.CreatorTypeUser},
+					{ID: "i2", Creator: types.CreatorTypeAssistant, State: types.InteractionStateWaiting},
+				},
+			}, nil)
+			storeMock.EXPECT().UpdateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, session types.Session) (*types.Session, error) {
+					return &session, nil
+				})
+
+			tt.response.SessionID = "ses_1"
+			_, err = c.HandleRunnerResponse(ctx, tt.response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, notifier.events)
+		})
+	}
+}
+
+func TestPrepareSession_NotifiesApprovalRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	storeMock.EXPECT().UpdateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, session types.Session) (*types.Session, error) {
+			return &session, nil
+		})
+
+	notifier := &recordingNotifier{}
+
+	c := &Controller{
+		Options: Options{
+			Config:   &config.ServerConfig{},
+			Store:    storeMock,
+			Notifier: notifier,
+		},
+	}
+
+	// data prep finished with a failed chunk, so finetuning waits for the user
+	session, err := c.PrepareSession(context.Background(), &types.Session{
+		ID:    "ses_01j9xw7e6gq",
+		Owner: "user_id",
+		Type:  types.SessionTypeText,
+		Mode:  types.SessionModeFinetune,
+		Metadata: types.SessionMetadata{
+			TextFinetuneEnabled: true,
+		},
+		Interactions: []*types.Interaction{
+			{ID: "i1", Creator: types.CreatorTypeUser},
+			{
+				ID:      "i2",
+				Creator: types.CreatorTypeAssistant,
+				DataPrepChunks: map[string][]types.DataPrepChunk{
+					"doc.txt": {{Index: 0, Error: "rate limited"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, session)
+	require.Equal(t, []notification.Event{notification.EventApprovalRequired}, notifier.events)
+}
@@ -17,6 +17,10 @@ func (c *Controller) runActionInteraction(ctx context.Context, session *types.Se
 		return nil, fmt.Errorf("action not found in interaction metadata")
 	}
 
+	if err := c.CheckAgentsPaused(ctx, session.Owner); err != nil {
+		return nil, err
+	}
+
 	var tool *types.Tool
 	var err error
 
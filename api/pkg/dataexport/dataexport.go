@@ -0,0 +1,297 @@
+package dataexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+
+	watermarkFile = "_watermark.json"
+	schemaFile    = "_schema.json"
+
+	exportLockKey = "dataexport"
+)
+
+// Watermark is the position of the last exported row of a table, exports
+// resume strictly after it
+type Watermark struct {
+	Time    time.Time `json:"time"`
+	ID      string    `json:"id"`
+	Updated time.Time `json:"updated"`
+}
+
+// Exporter incrementally writes the configured tables into the filestore, or
+// the customer's own bucket, as CSV, JSONL or Parquet files, one file per
+// batch, alongside a schema description so that warehouses can load them as
+// external tables
+type Exporter struct {
+	cfg       config.DataExport
+	basePath  string
+	store     store.Store
+	filestore filestore.FileStore
+	tables    map[string]*table
+	now       func() time.Time
+}
+
+func New(cfg *config.ServerConfig, store store.Store, fs filestore.FileStore) (*Exporter, error) {
+	switch cfg.DataExport.Format {
+	case FormatCSV, FormatJSONL, FormatParquet:
+	default:
+		return nil, fmt.Errorf("unknown data export format '%s', expected csv, jsonl or parquet", cfg.DataExport.Format)
+	}
+
+	if cfg.DataExport.BatchSize <= 0 {
+		return nil, fmt.Errorf("data export batch size must be positive")
+	}
+
+	tables := map[string]*table{}
+	for _, name := range cfg.DataExport.Tables {
+		t, ok := tableDefinitions[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown data export table '%s'", name)
+		}
+		tables[name] = t
+	}
+
+	if cfg.DataExport.GCSBucket != "" && cfg.DataExport.S3Bucket != "" {
+		return nil, fmt.Errorf("data export can write to a GCS or an S3 bucket, not both")
+	}
+
+	// the customer's bucket only holds exports, Helix's filestore is shared
+	basePath := filepath.Join(cfg.Controller.FilePrefixGlobal, cfg.DataExport.Path)
+	if cfg.DataExport.GCSBucket != "" || cfg.DataExport.S3Bucket != "" {
+		basePath = cfg.DataExport.Path
+	}
+
+	return &Exporter{
+		cfg:       cfg.DataExport,
+		basePath:  basePath,
+		store:     store,
+		filestore: fs,
+		tables:    tables,
+		now:       time.Now,
+	}, nil
+}
+
+// Start runs an export immediately and then on every configured interval until
+// the context is cancelled. Every API replica runs Start, a lock makes sure
+// only one of them exports at a time.
+func (e *Exporter) Start(ctx context.Context) {
+	for {
+		err := e.exportLocked(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("data export failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.cfg.Interval):
+		}
+	}
+}
+
+func (e *Exporter) exportLocked(ctx context.Context) error {
+	release, ok, err := e.store.TryAcquireLock(ctx, exportLockKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Debug().Msg("data export is running on another replica, skipping")
+		return nil
+	}
+	defer release()
+
+	return e.Export(ctx)
+}
+
+// Export writes all rows added since the last export for every configured table
+func (e *Exporter) Export(ctx context.Context) error {
+	for name, t := range e.tables {
+		rows, err := e.exportTable(ctx, name, t)
+		if err != nil {
+			return fmt.Errorf("failed to export table %s: %w", name, err)
+		}
+
+		log.Info().
+			Str("table", name).
+			Int("rows", rows).
+			Msg("data export complete")
+	}
+	return nil
+}
+
+func (e *Exporter) exportTable(ctx context.Context, name string, t *table) (int, error) {
+	tablePath := filepath.Join(e.basePath, name)
+
+	if err := e.writeSchema(ctx, tablePath, t); err != nil {
+		return 0, err
+	}
+
+	watermark, err := e.readWatermark(ctx, tablePath)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		batch, err := t.fetch(ctx, e.store, &store.ExportQuery{
+			AfterTime: watermark.Time,
+			AfterID:   watermark.ID,
+			Limit:     e.cfg.BatchSize,
+		})
+		if err != nil {
+			return total, err
+		}
+
+		if len(batch.rows) == 0 {
+			return total, nil
+		}
+
+		now := e.now().UTC()
+		filePath := filepath.Join(tablePath, now.Format("2006/01/02"), fmt.Sprintf("%s-%d-%s.%s", name, now.UnixNano(), batch.lastID, e.cfg.Format))
+
+		data, err := e.encode(t, batch.rows)
+		if err != nil {
+			return total, err
+		}
+
+		if _, err := e.filestore.WriteFile(ctx, filePath, bytes.NewReader(data)); err != nil {
+			return total, fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+
+		watermark = &Watermark{Time: batch.lastTime, ID: batch.lastID, Updated: now}
+		if err := e.writeWatermark(ctx, tablePath, watermark); err != nil {
+			return total, err
+		}
+
+		total += len(batch.rows)
+
+		if len(batch.rows) < e.cfg.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (e *Exporter) encode(t *table, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch e.cfg.Format {
+	case FormatParquet:
+		columns := make([]string, 0, len(t.columns))
+		for _, column := range t.columns {
+			columns = append(columns, column.Name)
+		}
+		data, err := encodeParquet(columns, rows)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	case FormatJSONL:
+		enc := json.NewEncoder(&buf)
+		for _, row := range rows {
+			record := make(map[string]string, len(t.columns))
+			for i, column := range t.columns {
+				record[column.Name] = row[i]
+			}
+			if err := enc.Encode(record); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		w := csv.NewWriter(&buf)
+		header := make([]string, 0, len(t.columns))
+		for _, column := range t.columns {
+			header = append(header, column.Name)
+		}
+		if err := w.Write(header); err != nil {
+			return nil, err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *Exporter) writeSchema(ctx context.Context, tablePath string, t *table) error {
+	schema := &Schema{
+		Table:       t.name,
+		Description: t.description,
+		Format:      e.cfg.Format,
+		Columns:     t.columns,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = e.filestore.WriteFile(ctx, filepath.Join(tablePath, schemaFile), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
+
+func (e *Exporter) readWatermark(ctx context.Context, tablePath string) (*Watermark, error) {
+	watermarkPath := filepath.Join(tablePath, watermarkFile)
+
+	if _, err := e.filestore.Get(ctx, watermarkPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, storage.ErrObjectNotExist) {
+			// first export of this table
+			return &Watermark{}, nil
+		}
+		return nil, fmt.Errorf("failed to check watermark: %w", err)
+	}
+
+	r, err := e.filestore.OpenFile(ctx, watermarkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watermark: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark: %w", err)
+	}
+
+	var watermark Watermark
+	if err := json.Unmarshal(data, &watermark); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark: %w", err)
+	}
+	return &watermark, nil
+}
+
+func (e *Exporter) writeWatermark(ctx context.Context, tablePath string, watermark *Watermark) error {
+	data, err := json.Marshal(watermark)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.filestore.WriteFile(ctx, filepath.Join(tablePath, watermarkFile), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to write watermark: %w", err)
+	}
+	return nil
+}
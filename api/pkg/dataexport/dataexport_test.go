@@ -0,0 +1,329 @@
+package dataexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newTestConfig(format string, batchSize int) *config.ServerConfig {
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+	cfg.DataExport = config.DataExport{
+		Format:    format,
+		Path:      "exports",
+		Tables:    []string{"llm_calls"},
+		BatchSize: batchSize,
+		Interval:  time.Hour,
+	}
+	return cfg
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(newTestConfig("xml", 10), nil, nil)
+	require.Error(t, err)
+
+	_, err = New(newTestConfig(FormatCSV, 0), nil, nil)
+	require.Error(t, err)
+
+	cfg := newTestConfig(FormatCSV, 10)
+	cfg.DataExport.Tables = []string{"secrets"}
+	_, err = New(cfg, nil, nil)
+	require.Error(t, err)
+
+	_, err = New(newTestConfig(FormatJSONL, 10), nil, nil)
+	require.NoError(t, err)
+
+	_, err = New(newTestConfig(FormatParquet, 10), nil, nil)
+	require.NoError(t, err)
+}
+
+func TestNew_CustomerBucket(t *testing.T) {
+	cfg := newTestConfig(FormatCSV, 10)
+	exporter, err := New(cfg, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "dev/exports", exporter.basePath)
+
+	// the customer's bucket isn't shared, exports go under the path only
+	cfg.DataExport.GCSBucket = "customer-warehouse"
+	exporter, err = New(cfg, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "exports", exporter.basePath)
+
+	cfg.DataExport.GCSBucket = ""
+	cfg.DataExport.S3Bucket = "customer-warehouse"
+	exporter, err = New(cfg, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "exports", exporter.basePath)
+
+	cfg.DataExport.GCSBucket = "customer-warehouse"
+	_, err = New(cfg, nil, nil)
+	require.Error(t, err)
+}
+
+// memoryFiles records what the exporter writes to the filestore mock
+type memoryFiles map[string][]byte
+
+func (m memoryFiles) expect(fs *filestore.MockFileStore) {
+	fs.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, path string, r io.Reader) (filestore.Item, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return filestore.Item{}, err
+			}
+			m[path] = data
+			return filestore.Item{Path: path}, nil
+		}).AnyTimes()
+	fs.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, path string) (filestore.Item, error) {
+			if _, ok := m[path]; !ok {
+				return filestore.Item{}, fmt.Errorf("failed to stat %s: %w", path, os.ErrNotExist)
+			}
+			return filestore.Item{Path: path}, nil
+		}).AnyTimes()
+	fs.EXPECT().OpenFile(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, path string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(m[path])), nil
+		}).AnyTimes()
+}
+
+func (m memoryFiles) dataFiles() []string {
+	var files []string
+	for path := range m {
+		if !strings.HasSuffix(path, watermarkFile) && !strings.HasSuffix(path, schemaFile) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+func TestExport_LLMCallsIncremental(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	files := memoryFiles{}
+	files.expect(fsMock)
+
+	exporter, err := New(newTestConfig(FormatCSV, 2), storeMock, fsMock)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	exporter.now = func() time.Time { return now }
+
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	calls := []*types.LLMCall{
+		{ID: "call_1", Created: created, AppID: "app_1", Model: "llama3", TotalTokens: 10},
+		{ID: "call_2", Created: created.Add(time.Minute), AppID: "app_1", Model: "llama3", TotalTokens: 20},
+		{ID: "call_3", Created: created.Add(2 * time.Minute), AppID: "app_2", Model: "llama3", TotalTokens: 30},
+	}
+
+	// first batch is full so a second is requested starting after call_2
+	gomock.InOrder(
+		storeMock.EXPECT().ListLLMCallsForExport(gomock.Any(), &store.ExportQuery{Limit: 2}).Return(calls[:2], nil),
+		storeMock.EXPECT().ListLLMCallsForExport(gomock.Any(), &store.ExportQuery{
+			AfterTime: calls[1].Created,
+			AfterID:   "call_2",
+			Limit:     2,
+		}).Return(calls[2:], nil),
+	)
+
+	err = exporter.Export(context.Background())
+	require.NoError(t, err)
+
+	dataFiles := files.dataFiles()
+	sort.Strings(dataFiles)
+	require.Equal(t, []string{
+		"dev/exports/llm_calls/2026/03/04/llm_calls-1772600767000000000-call_2.csv",
+		"dev/exports/llm_calls/2026/03/04/llm_calls-1772600767000000000-call_3.csv",
+	}, dataFiles)
+
+	lines := strings.Split(strings.TrimSpace(string(files[dataFiles[0]])), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "id,created,app_id,user_id,session_id,interaction_id,model,provider,step,duration_ms,prompt_tokens,completion_tokens,total_tokens", lines[0])
+	require.Equal(t, "call_1,2026-03-01T00:00:00Z,app_1,,,,llama3,,,0,0,0,10", lines[1])
+
+	lines = strings.Split(strings.TrimSpace(string(files[dataFiles[1]])), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "call_3,2026-03-01T00:02:00Z,app_2,,,,llama3,,,0,0,0,30", lines[1])
+
+	var watermark Watermark
+	err = json.Unmarshal(files["dev/exports/llm_calls/"+watermarkFile], &watermark)
+	require.NoError(t, err)
+	require.Equal(t, "call_3", watermark.ID)
+	require.True(t, watermark.Time.Equal(calls[2].Created))
+
+	var schema Schema
+	err = json.Unmarshal(files["dev/exports/llm_calls/"+schemaFile], &schema)
+	require.NoError(t, err)
+	require.Equal(t, "llm_calls", schema.Table)
+	require.Equal(t, FormatCSV, schema.Format)
+
+	// the next run resumes from the stored watermark
+	storeMock.EXPECT().ListLLMCallsForExport(gomock.Any(), &store.ExportQuery{
+		AfterTime: calls[2].Created,
+		AfterID:   "call_3",
+		Limit:     2,
+	}).Return(nil, nil)
+
+	err = exporter.Export(context.Background())
+	require.NoError(t, err)
+}
+
+func TestEncode_JSONL(t *testing.T) {
+	exporter, err := New(newTestConfig(FormatJSONL, 10), nil, nil)
+	require.NoError(t, err)
+
+	data, err := exporter.encode(llmCallsTable, [][]string{
+		{"call_1", "2026-03-01T00:00:00Z", "app_1", "user_1", "ses_1", "int_1", "llama3", "helix", "", "100", "1", "2", "3"},
+	})
+	require.NoError(t, err)
+
+	var record map[string]string
+	err = json.Unmarshal(bytes.TrimSpace(data), &record)
+	require.NoError(t, err)
+	require.Equal(t, "call_1", record["id"])
+	require.Equal(t, "helix", record["provider"])
+	require.Equal(t, "3", record["total_tokens"])
+}
+
+func TestEncode_Parquet(t *testing.T) {
+	exporter, err := New(newTestConfig(FormatParquet, 10), nil, nil)
+	require.NoError(t, err)
+
+	data, err := exporter.encode(llmCallsTable, [][]string{
+		{"call_1", "2026-03-01T00:00:00Z", "app_1", "user_1", "ses_1", "int_1", "llama3", "helix", "", "100", "1", "2", "3"},
+	})
+	require.NoError(t, err)
+
+	records := readParquet(t, data)
+	require.Len(t, records, 1)
+	require.Len(t, records[0], len(llmCallsTable.columns))
+	require.Equal(t, "call_1", records[0]["id"])
+	require.Equal(t, "helix", records[0]["provider"])
+	require.Equal(t, "3", records[0]["total_tokens"])
+}
+
+func TestExport_ScriptRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	files := memoryFiles{}
+	files.expect(fsMock)
+
+	cfg := newTestConfig(FormatCSV, 10)
+	cfg.DataExport.Tables = []string{"script_runs"}
+	exporter, err := New(cfg, storeMock, fsMock)
+	require.NoError(t, err)
+
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	storeMock.EXPECT().ListScriptRunsForExport(gomock.Any(), &store.ExportQuery{Limit: 10}).Return([]*types.ScriptRun{
+		{
+			ID:         "run_1",
+			Created:    created,
+			Owner:      "user_1",
+			OwnerType:  types.OwnerTypeUser,
+			AppID:      "app_1",
+			Type:       types.GptScriptRunnerTaskTypeTool,
+			State:      types.ScriptRunStateError,
+			DurationMs: 120,
+			Request:    &types.GptScriptRunnerRequest{},
+		},
+	}, nil)
+
+	err = exporter.Export(context.Background())
+	require.NoError(t, err)
+
+	dataFiles := files.dataFiles()
+	require.Len(t, dataFiles, 1)
+
+	lines := strings.Split(strings.TrimSpace(string(files[dataFiles[0]])), "\n")
+	require.Equal(t, []string{
+		"id,created,owner,owner_type,app_id,type,state,retries,duration_ms,system_error",
+		"run_1,2026-03-01T00:00:00Z,user_1,user,app_1,tool,error,0,120,",
+	}, lines)
+}
+
+func TestExportLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	files := memoryFiles{}
+	files.expect(fsMock)
+
+	exporter, err := New(newTestConfig(FormatCSV, 10), storeMock, fsMock)
+	require.NoError(t, err)
+
+	// another replica holds the lock, nothing is exported
+	storeMock.EXPECT().TryAcquireLock(gomock.Any(), exportLockKey).Return(nil, false, nil)
+
+	err = exporter.exportLocked(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	released := false
+	storeMock.EXPECT().TryAcquireLock(gomock.Any(), exportLockKey).Return(func() { released = true }, true, nil)
+	storeMock.EXPECT().ListLLMCallsForExport(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *store.ExportQuery) ([]*types.LLMCall, error) {
+			require.False(t, released, "export must run while the lock is held")
+			return nil, nil
+		})
+
+	err = exporter.exportLocked(context.Background())
+	require.NoError(t, err)
+	require.True(t, released)
+}
+
+func TestExport_SessionsMarksArchived(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	fsMock := filestore.NewMockFileStore(ctrl)
+
+	files := memoryFiles{}
+	files.expect(fsMock)
+
+	cfg := newTestConfig(FormatJSONL, 10)
+	cfg.DataExport.Tables = []string{"sessions"}
+	exporter, err := New(cfg, storeMock, fsMock)
+	require.NoError(t, err)
+
+	updated := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	archivedAt := updated.Add(24 * time.Hour)
+	storeMock.EXPECT().ListSessionsForExport(gomock.Any(), gomock.Any()).Return([]*types.Session{
+		{ID: "ses_1", Updated: updated},
+		{ID: "ses_2", Updated: updated, ArchivedAt: &archivedAt},
+	}, nil)
+
+	err = exporter.Export(context.Background())
+	require.NoError(t, err)
+
+	dataFiles := files.dataFiles()
+	require.Len(t, dataFiles, 1)
+
+	var records []map[string]string
+	for _, line := range strings.Split(strings.TrimSpace(string(files[dataFiles[0]])), "\n") {
+		var record map[string]string
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	require.Equal(t, "", records[0]["archived_at"])
+	require.Equal(t, "2026-03-02T00:00:00Z", records[1]["archived_at"])
+}
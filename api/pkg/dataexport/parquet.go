@@ -0,0 +1,49 @@
+package dataexport
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// encodeParquet writes a batch as a single row group file, every column is a
+// required UTF8 string. The column types are described in the schema file.
+func encodeParquet(columns []string, rows [][]string) ([]byte, error) {
+	group := make(parquet.Group, len(columns))
+	for _, name := range columns {
+		group[name] = parquet.String()
+	}
+	schema := parquet.NewSchema("schema", group)
+
+	// the schema orders its leaves by name, map the table columns onto them
+	leaves := make([]int, len(columns))
+	for i, name := range columns {
+		leaf, ok := schema.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("column '%s' missing from parquet schema", name)
+		}
+		leaves[i] = leaf.ColumnIndex
+	}
+
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, schema, parquet.CreatedBy("helix", "", ""))
+
+	parquetRows := make([]parquet.Row, 0, len(rows))
+	for _, row := range rows {
+		parquetRow := make(parquet.Row, len(columns))
+		for i, value := range row {
+			parquetRow[leaves[i]] = parquet.ByteArrayValue([]byte(value)).Level(0, 0, leaves[i])
+		}
+		parquetRows = append(parquetRows, parquetRow)
+	}
+
+	if _, err := w.WriteRows(parquetRows); err != nil {
+		return nil, fmt.Errorf("failed to write parquet rows: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close parquet writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+package dataexport
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+// readParquet reads a file back with the parquet library, rows are returned as
+// column name -> value maps
+func readParquet(t *testing.T, data []byte) []map[string]string {
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	r := parquet.NewReader(file)
+	defer r.Close()
+
+	var records []map[string]string
+	rows := make([]parquet.Row, 10)
+	for {
+		n, err := r.ReadRows(rows)
+		for _, row := range rows[:n] {
+			record := map[string]string{}
+			for _, value := range row {
+				record[file.Schema().Columns()[value.Column()][0]] = string(value.ByteArray())
+			}
+			records = append(records, record)
+		}
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestEncodeParquet(t *testing.T) {
+	columns := []string{"model", "id"}
+	rows := [][]string{
+		{"llama3", "call_1"},
+		{"", "call_2"},
+		{"qwen", "call_3"},
+	}
+
+	data, err := encodeParquet(columns, rows)
+	require.NoError(t, err)
+
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), file.NumRows())
+
+	for _, name := range columns {
+		leaf, ok := file.Schema().Lookup(name)
+		require.True(t, ok, name)
+		require.Equal(t, parquet.String().Type(), leaf.Node.Type(), name)
+		require.True(t, leaf.Node.Required(), name)
+	}
+
+	require.Equal(t, []map[string]string{
+		{"id": "call_1", "model": "llama3"},
+		{"id": "call_2", "model": ""},
+		{"id": "call_3", "model": "qwen"},
+	}, readParquet(t, data))
+}
+
+func TestEncodeParquet_Empty(t *testing.T) {
+	data, err := encodeParquet([]string{"id"}, nil)
+	require.NoError(t, err)
+
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), file.NumRows())
+}
@@ -0,0 +1,198 @@
+package dataexport
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+)
+
+// Column documents one exported field, types are warehouse-neutral
+type Column struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// Schema is written next to each table's export files
+type Schema struct {
+	Table       string   `json:"table"`
+	Description string   `json:"description"`
+	Format      string   `json:"format"`
+	Columns     []Column `json:"columns"`
+}
+
+type batch struct {
+	rows     [][]string
+	lastTime time.Time
+	lastID   string
+}
+
+type table struct {
+	name        string
+	description string
+	columns     []Column
+	fetch       func(ctx context.Context, s store.Store, q *store.ExportQuery) (*batch, error)
+}
+
+var tableDefinitions = map[string]*table{
+	"llm_calls":   llmCallsTable,
+	"sessions":    sessionsTable,
+	"script_runs": scriptRunsTable,
+}
+
+var llmCallsTable = &table{
+	name:        "llm_calls",
+	description: "One row per LLM call made by the platform, exported incrementally by creation time. Request and response bodies are not exported.",
+	columns: []Column{
+		{Name: "id", Type: "string", Description: "LLM call ID"},
+		{Name: "created", Type: "timestamp", Description: "When the call was made (RFC3339, UTC)"},
+		{Name: "app_id", Type: "string", Description: "App that made the call, empty for direct inference"},
+		{Name: "user_id", Type: "string", Description: "User the call was made for"},
+		{Name: "session_id", Type: "string", Description: "Session the call belongs to"},
+		{Name: "interaction_id", Type: "string", Description: "Interaction the call belongs to"},
+		{Name: "model", Type: "string", Description: "Model name"},
+		{Name: "provider", Type: "string", Description: "Inference provider"},
+		{Name: "step", Type: "string", Description: "Which step of the request made the call (e.g. is_actionable, generate_title)"},
+		{Name: "duration_ms", Type: "integer", Description: "Call duration in milliseconds"},
+		{Name: "prompt_tokens", Type: "integer", Description: "Prompt tokens used"},
+		{Name: "completion_tokens", Type: "integer", Description: "Completion tokens used"},
+		{Name: "total_tokens", Type: "integer", Description: "Total tokens used"},
+	},
+	fetch: func(ctx context.Context, s store.Store, q *store.ExportQuery) (*batch, error) {
+		calls, err := s.ListLLMCallsForExport(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		b := &batch{}
+		for _, call := range calls {
+			b.rows = append(b.rows, []string{
+				call.ID,
+				formatTime(call.Created),
+				call.AppID,
+				call.UserID,
+				call.SessionID,
+				call.InteractionID,
+				call.Model,
+				call.Provider,
+				string(call.Step),
+				strconv.FormatInt(call.DurationMs, 10),
+				strconv.FormatInt(call.PromptTokens, 10),
+				strconv.FormatInt(call.CompletionTokens, 10),
+				strconv.FormatInt(call.TotalTokens, 10),
+			})
+			b.lastTime = call.Created
+			b.lastID = call.ID
+		}
+		return b, nil
+	},
+}
+
+var sessionsTable = &table{
+	name:        "sessions",
+	description: "One row per session version, exported incrementally by update time. A session that changes is exported again, deduplicate on id keeping the latest updated.",
+	columns: []Column{
+		{Name: "id", Type: "string", Description: "Session ID"},
+		{Name: "created", Type: "timestamp", Description: "When the session was created (RFC3339, UTC)"},
+		{Name: "updated", Type: "timestamp", Description: "When the session was last updated (RFC3339, UTC)"},
+		{Name: "owner", Type: "string", Description: "Owner ID"},
+		{Name: "owner_type", Type: "string", Description: "Owner type (e.g. user)"},
+		{Name: "parent_app", Type: "string", Description: "App the session was started from"},
+		{Name: "mode", Type: "string", Description: "Session mode (inference | finetune)"},
+		{Name: "type", Type: "string", Description: "Session type (text | image)"},
+		{Name: "model_name", Type: "string", Description: "Model used by the session"},
+		{Name: "interaction_count", Type: "integer", Description: "Number of interactions, for archived sessions only the last exchange is counted"},
+		{Name: "last_state", Type: "string", Description: "State of the last interaction (waiting | editing | complete | error)"},
+		{Name: "last_error", Type: "string", Description: "Error of the last interaction, if any"},
+		{Name: "archived_at", Type: "timestamp", Description: "When the session's interactions were moved to the session archive, empty if they weren't (RFC3339, UTC)"},
+	},
+	fetch: func(ctx context.Context, s store.Store, q *store.ExportQuery) (*batch, error) {
+		sessions, err := s.ListSessionsForExport(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		b := &batch{}
+		for _, session := range sessions {
+			var lastState, lastError, archivedAt string
+			if session.ArchivedAt != nil {
+				archivedAt = formatTime(*session.ArchivedAt)
+			}
+			if len(session.Interactions) > 0 {
+				last := session.Interactions[len(session.Interactions)-1]
+				lastState = string(last.State)
+				lastError = last.Error
+			}
+
+			b.rows = append(b.rows, []string{
+				session.ID,
+				formatTime(session.Created),
+				formatTime(session.Updated),
+				session.Owner,
+				string(session.OwnerType),
+				session.ParentApp,
+				string(session.Mode),
+				string(session.Type),
+				session.ModelName,
+				strconv.Itoa(len(session.Interactions)),
+				lastState,
+				lastError,
+				archivedAt,
+			})
+			b.lastTime = session.Updated
+			b.lastID = session.ID
+		}
+		return b, nil
+	},
+}
+
+var scriptRunsTable = &table{
+	name:        "script_runs",
+	description: "Audit trail of tool and script executions, one row per run, exported incrementally by creation time. Request and response bodies are not exported.",
+	columns: []Column{
+		{Name: "id", Type: "string", Description: "Script run ID"},
+		{Name: "created", Type: "timestamp", Description: "When the run started (RFC3339, UTC)"},
+		{Name: "owner", Type: "string", Description: "Owner ID"},
+		{Name: "owner_type", Type: "string", Description: "Owner type (e.g. user)"},
+		{Name: "app_id", Type: "string", Description: "App the script belongs to"},
+		{Name: "type", Type: "string", Description: "Run type (tool | github_app)"},
+		{Name: "state", Type: "string", Description: "Outcome of the run (complete | error)"},
+		{Name: "retries", Type: "integer", Description: "Number of retries"},
+		{Name: "duration_ms", Type: "integer", Description: "Run duration in milliseconds"},
+		{Name: "system_error", Type: "string", Description: "Error if the runner didn't respond"},
+	},
+	fetch: func(ctx context.Context, s store.Store, q *store.ExportQuery) (*batch, error) {
+		runs, err := s.ListScriptRunsForExport(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		b := &batch{}
+		for _, run := range runs {
+			b.rows = append(b.rows, []string{
+				run.ID,
+				formatTime(run.Created),
+				run.Owner,
+				string(run.OwnerType),
+				run.AppID,
+				string(run.Type),
+				string(run.State),
+				strconv.Itoa(run.Retries),
+				strconv.Itoa(run.DurationMs),
+				run.SystemError,
+			})
+			b.lastTime = run.Created
+			b.lastID = run.ID
+		}
+		return b, nil
+	},
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
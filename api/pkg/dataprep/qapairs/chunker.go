@@ -0,0 +1,36 @@
+package qapairs
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// estimateTokens approximates the token count of s. We don't have the
+// tokenizer of every target model, ~4 characters per token holds for English
+// text with the common BPE vocabularies and errs on the side of smaller chunks.
+func estimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// SplitText splits texts longer than chunkTokens (estimated) tokens into
+// chunks that overlap by overlapTokens, preferring paragraph, line and word
+// boundaries. A chunkTokens of 0 disables chunking.
+func SplitText(contents string, chunkTokens, overlapTokens int) ([]string, error) {
+	if chunkTokens <= 0 || estimateTokens(contents) <= chunkTokens {
+		return []string{contents}, nil
+	}
+
+	if overlapTokens < 0 || overlapTokens >= chunkTokens {
+		return nil, fmt.Errorf("chunk overlap (%d) must be between 0 and the chunk size (%d)", overlapTokens, chunkTokens)
+	}
+
+	splitter := textsplitter.NewRecursiveCharacter(
+		textsplitter.WithChunkSize(chunkTokens),
+		textsplitter.WithChunkOverlap(overlapTokens),
+		textsplitter.WithLenFunc(estimateTokens),
+	)
+
+	return splitter.SplitText(contents)
+}
@@ -0,0 +1,49 @@
+package qapairs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 50; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("Paragraph %d talks about sharks and their feeding habits in some detail.", i))
+	}
+	contents := strings.Join(paragraphs, "\n\n")
+
+	t.Run("ShortTextIsNotSplit", func(t *testing.T) {
+		chunks, err := SplitText(paragraphs[0], 100, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{paragraphs[0]}, chunks)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		chunks, err := SplitText(contents, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, chunks, 1)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		chunks, err := SplitText(contents, 100, 20)
+		require.NoError(t, err)
+		require.Greater(t, len(chunks), 5)
+
+		for _, chunk := range chunks {
+			require.LessOrEqual(t, estimateTokens(chunk), 100)
+		}
+
+		// Chunks overlap and nothing is lost
+		require.Contains(t, chunks[1], strings.Split(chunks[0], "\n\n")[len(strings.Split(chunks[0], "\n\n"))-1])
+		require.True(t, strings.HasPrefix(chunks[0], "Paragraph 0 "))
+		require.True(t, strings.HasSuffix(chunks[len(chunks)-1], "Paragraph 49 talks about sharks and their feeding habits in some detail."))
+	})
+
+	t.Run("InvalidOverlap", func(t *testing.T) {
+		_, err := SplitText(contents, 100, 100)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,159 @@
+package qapairs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/tools"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/rs/zerolog/log"
+	ext_openai "github.com/sashabaranov/go-openai"
+)
+
+type JudgeConfig struct {
+	System string `yaml:"system"`
+	User   string `yaml:"user"`
+	// Pairs scoring below this on faithfulness or answerability are dropped
+	MinScore int `yaml:"min_score"`
+}
+
+type JudgeTemplateData struct {
+	DocumentChunk string
+	Question      string
+	Answer        string
+}
+
+// Judgement is the judge model's verdict on a generated pair
+type Judgement struct {
+	Question      string `json:"question"`
+	Answer        string `json:"answer"`
+	Faithfulness  int    `json:"faithfulness"`
+	Answerability int    `json:"answerability"`
+	Reason        string `json:"reason,omitempty"`
+	Accepted      bool   `json:"accepted"`
+	Error         string `json:"error,omitempty"`
+}
+
+type judge struct {
+	client    openai.Client
+	ownerID   string
+	sessionID string
+	model     string
+	minScore  int
+
+	system *template.Template
+	user   *template.Template
+}
+
+func newJudge(client openai.Client, ownerID, sessionID, model string, cfg JudgeConfig) (*judge, error) {
+	system, err := template.New("judgeSystemPrompt").Parse(cfg.System)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse judge system prompt: %w", err)
+	}
+	user, err := template.New("judgeUserPrompt").Parse(cfg.User)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse judge user prompt: %w", err)
+	}
+
+	return &judge{
+		client:    client,
+		ownerID:   ownerID,
+		sessionID: sessionID,
+		model:     model,
+		minScore:  cfg.MinScore,
+		system:    system,
+		user:      user,
+	}, nil
+}
+
+// judgeResult scores every pair of the result against the chunk it was
+// generated from and keeps only the accepted pairs. Pairs the judge fails to
+// score are dropped too, they are meant for fine-tuning.
+func (j *judge) judgeResult(result *Result, chunk string) {
+	accepted := []types.DataPrepTextQuestionRaw{}
+
+	for _, pair := range result.Pairs {
+		judgement, usage, err := j.judgePair(chunk, pair)
+		result.JudgeTokens += usage.TotalTokens
+		if err != nil {
+			log.Warn().Err(err).Str("question", pair.Question).Msg("failed to judge qapair, dropping it")
+			judgement.Error = err.Error()
+		}
+
+		if judgement.Accepted {
+			accepted = append(accepted, pair)
+		}
+		result.Judgements = append(result.Judgements, judgement)
+	}
+
+	result.Rejected = len(result.Pairs) - len(accepted)
+	result.Pairs = accepted
+}
+
+func (j *judge) judgePair(chunk string, pair types.DataPrepTextQuestionRaw) (*Judgement, ext_openai.Usage, error) {
+	judgement := &Judgement{
+		Question: pair.Question,
+		Answer:   pair.Answer,
+	}
+
+	data := JudgeTemplateData{
+		DocumentChunk: chunk,
+		Question:      pair.Question,
+		Answer:        pair.Answer,
+	}
+
+	var system, user bytes.Buffer
+	if err := j.system.Execute(&system, data); err != nil {
+		return judgement, ext_openai.Usage{}, err
+	}
+	if err := j.user.Execute(&user, data); err != nil {
+		return judgement, ext_openai.Usage{}, err
+	}
+
+	ctx := openai.SetContextValues(context.Background(), &openai.ContextValues{
+		OwnerID:       j.ownerID,
+		SessionID:     j.sessionID,
+		InteractionID: "n/a",
+	})
+
+	resp, err := j.client.CreateChatCompletion(ctx, ext_openai.ChatCompletionRequest{
+		Model: j.model,
+		Messages: []ext_openai.ChatCompletionMessage{
+			{
+				Role:    ext_openai.ChatMessageRoleSystem,
+				Content: system.String(),
+			},
+			{
+				Role:    ext_openai.ChatMessageRoleUser,
+				Content: user.String(),
+			},
+		},
+		ResponseFormat: &ext_openai.ChatCompletionResponseFormat{
+			Type: ext_openai.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	})
+	if err != nil {
+		return judgement, ext_openai.Usage{}, fmt.Errorf("ChatCompletion error (judge): %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return judgement, resp.Usage, fmt.Errorf("judge returned no choices")
+	}
+
+	answer := tools.AttemptFixJSON(resp.Choices[0].Message.Content)
+	if err := json.Unmarshal([]byte(answer), judgement); err != nil {
+		return judgement, resp.Usage, fmt.Errorf("error parsing judge JSON (%s): %w", answer, err)
+	}
+
+	// The judge must not rewrite the pair it was asked about
+	judgement.Question = pair.Question
+	judgement.Answer = pair.Answer
+	judgement.Accepted = judgement.Faithfulness >= j.minScore && judgement.Answerability >= j.minScore
+
+	return judgement, resp.Usage, nil
+}
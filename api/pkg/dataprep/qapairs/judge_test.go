@@ -0,0 +1,87 @@
+package qapairs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/types"
+
+	ext_openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
+)
+
+func judgeResponse(content string) ext_openai.ChatCompletionResponse {
+	return ext_openai.ChatCompletionResponse{
+		Choices: []ext_openai.ChatCompletionChoice{
+			{Message: ext_openai.ChatCompletionMessage{Content: content}},
+		},
+		Usage: ext_openai.Usage{TotalTokens: 10},
+	}
+}
+
+func TestJudgeResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := openai.NewMockClient(ctrl)
+
+	j, err := newJudge(client, "owner", "session", "judge-model", JudgeConfig{
+		System:   "Score the pair",
+		User:     "{{.DocumentChunk}}\nQ: {{.Question}}\nA: {{.Answer}}",
+		MinScore: 3,
+	})
+	require.NoError(t, err)
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req ext_openai.ChatCompletionRequest) (ext_openai.ChatCompletionResponse, error) {
+			require.Equal(t, "judge-model", req.Model)
+			require.Equal(t, ext_openai.ChatCompletionResponseFormatTypeJSONObject, req.ResponseFormat.Type)
+
+			user := req.Messages[1].Content
+			require.True(t, strings.HasPrefix(user, "Sharks have no bones."))
+
+			switch {
+			case strings.Contains(user, "Q: Do sharks have bones?"):
+				return judgeResponse("```json\n{\"faithfulness\": 5, \"answerability\": 4, \"reason\": \"stated\"}\n```"), nil
+			case strings.Contains(user, "Q: How fast do sharks swim?"):
+				return judgeResponse(`{"faithfulness": 1, "answerability": 2, "reason": "not in the document"}`), nil
+			default:
+				return judgeResponse("I can't score this"), nil
+			}
+		}).Times(3)
+
+	result := &Result{
+		Pairs: []types.DataPrepTextQuestionRaw{
+			{Question: "Do sharks have bones?", Answer: "No, their skeleton is cartilage."},
+			{Question: "How fast do sharks swim?", Answer: "Up to 70km/h."},
+			{Question: "What do sharks eat?", Answer: "Fish."},
+		},
+	}
+
+	j.judgeResult(result, "Sharks have no bones.")
+
+	require.Equal(t, []types.DataPrepTextQuestionRaw{
+		{Question: "Do sharks have bones?", Answer: "No, their skeleton is cartilage."},
+	}, result.Pairs)
+	require.Equal(t, 2, result.Rejected)
+	require.Equal(t, 30, result.JudgeTokens)
+
+	require.Len(t, result.Judgements, 3)
+	require.True(t, result.Judgements[0].Accepted)
+	require.Equal(t, 5, result.Judgements[0].Faithfulness)
+	require.False(t, result.Judgements[1].Accepted)
+	require.Equal(t, "not in the document", result.Judgements[1].Reason)
+	require.False(t, result.Judgements[2].Accepted)
+	require.NotEmpty(t, result.Judgements[2].Error)
+}
+
+func TestJudgeConfig(t *testing.T) {
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(qapairConfig), &config))
+	require.Equal(t, 3, config.Judge.MinScore)
+
+	_, err := newJudge(nil, "", "", "judge-model", config.Judge)
+	require.NoError(t, err)
+}
@@ -0,0 +1,151 @@
+package qapairs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// manifest records the result of every (target, prompt, text, chunk) query of
+// a run as it happens, so an interrupted run can be resumed without repeating
+// the successful ones. It is a JSONL file, the run's chunk settings followed by
+// Results, that is only ever appended to.
+type manifest struct {
+	f         *os.File
+	enc       *json.Encoder
+	completed map[string]*Result
+}
+
+// runSettings decide how texts are split, the chunk numbers in a manifest
+// only refer to the same chunks when a resumed run uses the same settings
+type runSettings struct {
+	ChunkTokens  int `json:"chunk_tokens"`
+	ChunkOverlap int `json:"chunk_overlap"`
+}
+
+func manifestPath(dir, runID string) string {
+	return filepath.Join(dir, runID+"_manifest.jsonl")
+}
+
+func manifestKey(target, prompt, text string, chunk int) string {
+	return fmt.Sprintf("%s/%s/%s/%d", target, prompt, text, chunk)
+}
+
+// openManifest creates the manifest of a new run or, when resuming, loads the
+// completed queries of an existing one run with the same settings
+func openManifest(dir, runID string, resume bool, settings runSettings) (*manifest, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	filename := manifestPath(dir, runID)
+
+	m := &manifest{
+		completed: make(map[string]*Result),
+	}
+
+	hasSettings := false
+	if resume {
+		var err error
+		hasSettings, err = m.load(filename, settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run manifest, error: %w", err)
+	}
+
+	m.f = f
+	m.enc = json.NewEncoder(f)
+
+	if !hasSettings {
+		if err := m.enc.Encode(&settings); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write run manifest, error: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// load reads the completed queries of the manifest, it returns whether the
+// manifest already starts with the run settings
+func (m *manifest) load(filename string, settings runSettings) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("no manifest found for run, expected %s", filename)
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+
+	var recorded runSettings
+	if err := dec.Decode(&recorded); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		// Killed before the settings were written, nothing has run yet
+		log.Warn().Err(err).Str("manifest", filename).Msg("dropping truncated run manifest settings")
+		return false, os.Truncate(filename, 0)
+	}
+	if recorded != settings {
+		return false, fmt.Errorf("run was chunked with %d tokens and %d overlap, resume it with the same settings",
+			recorded.ChunkTokens, recorded.ChunkOverlap)
+	}
+
+	for {
+		offset := dec.InputOffset()
+
+		var result Result
+		err := dec.Decode(&result)
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			// The run was most likely killed while writing the last result,
+			// drop it so that query is repeated and new results don't get
+			// appended to the broken line
+			log.Warn().Err(err).Str("manifest", filename).Msg("dropping truncated run manifest entry")
+			return true, os.Truncate(filename, offset)
+		}
+
+		m.complete(&result)
+	}
+}
+
+// complete marks the query of the result as done, failed queries are left to
+// be retried
+func (m *manifest) complete(result *Result) {
+	if result.Error != "" {
+		return
+	}
+	m.completed[manifestKey(result.Target, result.Prompt, result.Text, result.Chunk)] = result
+}
+
+func (m *manifest) get(target, prompt, text string, chunk int) (*Result, bool) {
+	result, ok := m.completed[manifestKey(target, prompt, text, chunk)]
+	return result, ok
+}
+
+func (m *manifest) add(result *Result) error {
+	if err := m.enc.Encode(result); err != nil {
+		return fmt.Errorf("failed to write run manifest, error: %w", err)
+	}
+	m.complete(result)
+	return nil
+}
+
+func (m *manifest) Close() error {
+	return m.f.Close()
+}
@@ -0,0 +1,94 @@
+package qapairs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRunSettings = runSettings{ChunkTokens: 1000, ChunkOverlap: 100}
+
+func TestManifest_Resume(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := openManifest(dir, "1700000000", false, testRunSettings)
+	require.NoError(t, err)
+
+	require.NoError(t, m.add(&Result{Target: "llama3", Prompt: "simple-quiz", Text: "sharks", Chunk: 0, Parsed: true,
+		Pairs: []types.DataPrepTextQuestionRaw{{Question: "q", Answer: "a"}}}))
+	require.NoError(t, m.add(&Result{Target: "llama3", Prompt: "simple-quiz", Text: "sharks", Chunk: 1, Error: "error parsing JSON"}))
+	require.NoError(t, m.Close())
+
+	// Simulate the run being killed halfway through writing the next result
+	f, err := os.OpenFile(manifestPath(dir, "1700000000"), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"target":"llama3","prompt":"simple-qu`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	m, err = openManifest(dir, "1700000000", true, testRunSettings)
+	require.NoError(t, err)
+	defer m.Close()
+
+	result, ok := m.get("llama3", "simple-quiz", "sharks", 0)
+	require.True(t, ok)
+	require.Equal(t, []types.DataPrepTextQuestionRaw{{Question: "q", Answer: "a"}}, result.Pairs)
+
+	// Failed queries are retried
+	_, ok = m.get("llama3", "simple-quiz", "sharks", 1)
+	require.False(t, ok)
+
+	_, ok = m.get("llama3", "simple-quiz", "sharks", 2)
+	require.False(t, ok)
+	_, ok = m.get("gpt-4o", "simple-quiz", "sharks", 0)
+	require.False(t, ok)
+
+	// Results of the resumed run are appended after the last complete one
+	require.NoError(t, m.add(&Result{Target: "llama3", Prompt: "simple-quiz", Text: "sharks", Chunk: 2, Parsed: true}))
+	require.NoError(t, m.Close())
+
+	m, err = openManifest(dir, "1700000000", true, testRunSettings)
+	require.NoError(t, err)
+	require.Len(t, m.completed, 2)
+}
+
+func TestManifest_ResumeWithOtherSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := openManifest(dir, "1700000000", false, testRunSettings)
+	require.NoError(t, err)
+	require.NoError(t, m.add(&Result{Target: "llama3", Prompt: "simple-quiz", Text: "sharks", Chunk: 0, Parsed: true}))
+	require.NoError(t, m.Close())
+
+	// Chunk 0 would be a different part of the text
+	_, err = openManifest(dir, "1700000000", true, runSettings{ChunkTokens: 500, ChunkOverlap: 100})
+	require.Error(t, err)
+
+	m, err = openManifest(dir, "1700000000", true, testRunSettings)
+	require.NoError(t, err)
+	require.NoError(t, m.Close())
+}
+
+func TestManifest_ResumeBeforeSettingsWritten(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(manifestPath(dir, "1700000000"), []byte(`{"chunk_tok`), 0644))
+
+	m, err := openManifest(dir, "1700000000", true, testRunSettings)
+	require.NoError(t, err)
+	require.Empty(t, m.completed)
+	require.NoError(t, m.Close())
+
+	// The settings are written again so the run can be resumed later
+	m, err = openManifest(dir, "1700000000", true, testRunSettings)
+	require.NoError(t, err)
+	require.NoError(t, m.Close())
+}
+
+func TestManifest_ResumeUnknownRun(t *testing.T) {
+	_, err := openManifest(t.TempDir(), "1700000000", true, testRunSettings)
+	require.Error(t, err)
+}
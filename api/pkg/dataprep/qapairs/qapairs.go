@@ -3,10 +3,14 @@ package qapairs
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
@@ -22,6 +26,9 @@ import (
 //go:embed qapair_config.yaml
 var qapairConfig string
 
+// runsDir is where the per query logs and the results of a run are written
+const runsDir = "runs"
+
 type Prompt struct {
 	Name       string                                             `yaml:"name"`
 	System     string                                             `yaml:"system"`
@@ -48,11 +55,39 @@ type Log struct {
 }
 
 type Config struct {
-	Prompts      []Prompt `yaml:"prompts"`
-	Texts        []Text   `yaml:"texts"`
-	Concurrency  int      `yaml:"concurrency"`
-	ChunkSize    int      `yaml:"chunk_size"`
-	NumQuestions int      `yaml:"num_questions"`
+	Prompts      []Prompt  `yaml:"prompts"`
+	Texts        []Text    `yaml:"texts"`
+	TextDirs     []TextDir `yaml:"text_dirs"`
+	Concurrency  int       `yaml:"concurrency"`
+	ChunkSize    int       `yaml:"chunk_size"`
+	NumQuestions int       `yaml:"num_questions"`
+	// Used by Run to split long texts, in (estimated) tokens
+	TextChunkTokens  int         `yaml:"text_chunk_tokens"`
+	TextChunkOverlap int         `yaml:"text_chunk_overlap"`
+	Judge            JudgeConfig `yaml:"judge"`
+}
+
+type RunOptions struct {
+	OwnerID   string
+	SessionID string
+	Model     string
+	// Prompt and text names to run, defaults to all
+	Prompts []string
+	Texts   []string
+	// External config file, see LoadConfig
+	ConfigFile string
+	// Globs of directories to discover more texts in, recursively
+	TextDirs []string
+	// Override the chunking of the config, in (estimated) tokens
+	ChunkTokens  int
+	ChunkOverlap int
+	// Model used to score the generated pairs, the judge pass is skipped
+	// when empty
+	JudgeModel    string
+	JudgeMinScore int
+	// ID of an interrupted run to resume, only the queries missing from its
+	// manifest are executed
+	Resume string
 }
 
 // TODO: maybe optimize (or at least factor!) to not read the yaml on every call
@@ -114,15 +149,38 @@ func FindPrompt(name string) (Prompt, error) {
 	return Prompt{}, fmt.Errorf("could not find prompt with name %s", name)
 }
 
-func Run(client openai.Client, ownerID, sessionID, model string, promptFilter, textFilter []string) error {
-	var config Config
-	err := yaml.Unmarshal([]byte(qapairConfig), &config)
+func Run(client openai.Client, opts RunOptions) error {
+	config, err := LoadConfig(opts.ConfigFile)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal qapair config: %v", err)
+		return err
+	}
+
+	textDirs := config.TextDirs
+	for _, path := range opts.TextDirs {
+		textDirs = append(textDirs, TextDir{Path: path, Recursive: true})
 	}
 
 	prompts := config.Prompts
 	texts := config.Texts
+	for _, dir := range textDirs {
+		discovered, err := discoverTexts(dir)
+		if err != nil {
+			return err
+		}
+		texts = append(texts, discovered...)
+	}
+	promptFilter := opts.Prompts
+	textFilter := opts.Texts
+	model := opts.Model
+
+	chunkTokens := config.TextChunkTokens
+	if opts.ChunkTokens > 0 {
+		chunkTokens = opts.ChunkTokens
+	}
+	chunkOverlap := config.TextChunkOverlap
+	if opts.ChunkOverlap > 0 {
+		chunkOverlap = opts.ChunkOverlap
+	}
 
 	filteredPrompts := []Prompt{}
 	if len(promptFilter) > 0 {
@@ -150,23 +208,114 @@ func Run(client openai.Client, ownerID, sessionID, model string, promptFilter, t
 		filteredTexts = texts
 	}
 
+	chunkedTexts := make([]chunkedText, 0, len(filteredTexts))
+	for _, text := range filteredTexts {
+		chunked, err := splitText(text, chunkTokens, chunkOverlap)
+		if err != nil {
+			return err
+		}
+		chunkedTexts = append(chunkedTexts, chunked)
+	}
+
+	var j *judge
+	if opts.JudgeModel != "" {
+		judgeConfig := config.Judge
+		if opts.JudgeMinScore > 0 {
+			judgeConfig.MinScore = opts.JudgeMinScore
+		}
+		j, err = newJudge(client, opts.OwnerID, opts.SessionID, opts.JudgeModel, judgeConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	runID := opts.Resume
+	if runID == "" {
+		runID = fmt.Sprintf("%d", time.Now().Unix())
+	}
+
+	m, err := openManifest(runsDir, runID, opts.Resume != "", runSettings{ChunkTokens: chunkTokens, ChunkOverlap: chunkOverlap})
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	fmt.Printf("Run %s, resume with --resume %s if interrupted\n", runID, runID)
+
+	var results []*Result
+
 	// for _, target := range filteredTargets {
 	for _, prompt := range filteredPrompts {
-		for _, text := range filteredTexts {
-			fmt.Printf("Running helix qapairs --target=\"%s\" --prompt=\"%s\" --text=\"%s\"\n", model, prompt.Name, text.Name)
-			resp, err := Query(client, ownerID, sessionID, model, prompt, text, "", "", 0)
-			if err != nil {
-				return fmt.Errorf("error querying model: %v", err)
+		for _, text := range chunkedTexts {
+			// Pairs of all chunks of the text
+			pairs := []types.DataPrepTextQuestionRaw{}
+
+			for i, chunk := range text.chunks {
+				if result, ok := m.get(model, prompt.Name, text.Name, i); ok {
+					fmt.Printf("Skipping helix qapairs --target=\"%s\" --prompt=\"%s\" --text=\"%s\" (chunk %d/%d), already completed\n", model, prompt.Name, text.Name, i+1, len(text.chunks))
+					pairs = append(pairs, result.Pairs...)
+					results = append(results, result)
+					continue
+				}
+
+				fmt.Printf("Running helix qapairs --target=\"%s\" --prompt=\"%s\" --text=\"%s\" (chunk %d/%d)\n", model, prompt.Name, text.Name, i+1, len(text.chunks))
+				result, err := query(client, opts.OwnerID, opts.SessionID, model, prompt, Text{Name: text.Name, Contents: chunk}, text.documentID, text.Name, config.NumQuestions, i)
+				if err != nil {
+					return fmt.Errorf("error querying model: %v", err)
+				}
+
+				if j != nil {
+					j.judgeResult(result, chunk)
+					fmt.Printf("Judge %s accepted %d/%d pairs\n", opts.JudgeModel, len(result.Pairs), len(result.Pairs)+result.Rejected)
+				}
+
+				if err := m.add(result); err != nil {
+					return err
+				}
+
+				pairs = append(pairs, result.Pairs...)
+				results = append(results, result)
 			}
-			bs, err := yaml.Marshal(resp)
+
+			bs, err := yaml.Marshal(pairs)
 			if err != nil {
-				return fmt.Errorf("error marshalling response to yaml (%v): %w ", resp, err)
+				return fmt.Errorf("error marshalling response to yaml (%v): %w ", pairs, err)
 			}
 			fmt.Println(string(bs))
 		}
 	}
 
-	return nil
+	return writeResults(os.Stdout, runsDir, runID, results)
+}
+
+type chunkedText struct {
+	Text
+	documentID string
+	chunks     []string
+}
+
+func splitText(text Text, chunkTokens, chunkOverlap int) (chunkedText, error) {
+	contents := text.Contents
+	if contents == "" {
+		var err error
+		contents, err = loadFile(text.File)
+		if err != nil {
+			return chunkedText{}, fmt.Errorf("failed to load file %s: %w", text.File, err)
+		}
+	}
+
+	chunks, err := SplitText(contents, chunkTokens, chunkOverlap)
+	if err != nil {
+		return chunkedText{}, fmt.Errorf("failed to split text %s: %w", text.Name, err)
+	}
+
+	hash := sha256.Sum256([]byte(contents))
+
+	return chunkedText{
+		Text:       text,
+		documentID: hex.EncodeToString(hash[:])[:10],
+		chunks:     chunks,
+	}, nil
 }
 
 type TemplateData struct {
@@ -177,7 +326,15 @@ type TemplateData struct {
 }
 
 func Query(client openai.Client, ownerID, sessionID, model string, prompt Prompt, text Text, documentID, documentGroupID string, numQuestions int) ([]types.DataPrepTextQuestionRaw, error) {
-	// Perform the query for the given target and prompt
+	result, err := query(client, ownerID, sessionID, model, prompt, text, documentID, documentGroupID, numQuestions, 0)
+	if err != nil {
+		return nil, err
+	}
+	return result.Pairs, nil
+}
+
+// query performs the query for the given target and prompt and records how it went
+func query(client openai.Client, ownerID, sessionID, model string, prompt Prompt, text Text, documentID, documentGroupID string, numQuestions, chunk int) (*Result, error) {
 	var (
 		contents string
 		err      error
@@ -230,31 +387,54 @@ func Query(client openai.Client, ownerID, sessionID, model string, prompt Prompt
 
 	userPrompt := buf2.String()
 
+	result := &Result{
+		Target: model,
+		Prompt: prompt.Name,
+		Text:   text.Name,
+		Chunk:  chunk,
+	}
+
 	startTime := time.Now()
 	debug := fmt.Sprintf("prompt %s", prompt.Name)
 	// try not enforcing json schema initially, only retry if we fail to parse
-	resp, err := chatWithModel(client, ownerID, sessionID, model, systemPrompt, userPrompt, debug, nil)
+	resp, usage, err := chatWithModel(client, ownerID, sessionID, model, systemPrompt, userPrompt, debug, nil)
+	result.addUsage(usage)
 	if err != nil {
 		log.Warn().Msgf("ChatCompletion error non-JSON mode, trying again (%s): %v\n", debug, err)
-		resp, err = chatWithModel(client, ownerID, sessionID, model, systemPrompt, userPrompt, debug, prompt.JSONSchema)
+		result.JSONMode = true
+		resp, usage, err = chatWithModel(client, ownerID, sessionID, model, systemPrompt, userPrompt, debug, prompt.JSONSchema)
+		result.addUsage(usage)
 		if err != nil {
 			log.Warn().Msgf("ChatCompletion error JSON mode, giving up, but not propagating the error further for now. (%s): %v\n", debug, err)
 			latency := time.Since(startTime).Milliseconds()
 			log.Warn().Msgf("Took: %.2f seconds. FAILED", float32(latency)/1000)
-			return []types.DataPrepTextQuestionRaw{}, nil
+
+			result.LatencyMs = latency
+			result.Error = err.Error()
+			result.Pairs = []types.DataPrepTextQuestionRaw{}
+			return result, nil
 		}
 	}
 	latency := time.Since(startTime).Milliseconds()
 
 	log.Info().Msgf("Took: %.2f seconds", float32(latency)/1000)
 
-	err = os.MkdirAll("runs", os.ModePerm)
+	result.LatencyMs = latency
+	result.Parsed = true
+	result.Pairs = resp
+
+	err = os.MkdirAll(runsDir, os.ModePerm)
 	if err != nil {
 		return nil, err
 	}
 
 	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("runs/%d_%s_%s.yaml", timestamp, prompt.Name, text.Name)
+	// Texts discovered in directories are named by their relative path
+	name := fmt.Sprintf("%d_%s_%s", timestamp, prompt.Name, strings.ReplaceAll(text.Name, "/", "_"))
+	if chunk > 0 {
+		name = fmt.Sprintf("%s_chunk%d", name, chunk)
+	}
+	filename := filepath.Join(runsDir, name+".yaml")
 
 	respBytes, err := yaml.Marshal(resp)
 	if err != nil {
@@ -284,7 +464,7 @@ func Query(client openai.Client, ownerID, sessionID, model string, prompt Prompt
 		return nil, fmt.Errorf("failed to write log file, error: %w", err)
 	}
 
-	return resp, nil
+	return result, nil
 }
 
 func loadFile(filePath string) (string, error) {
@@ -295,7 +475,7 @@ func loadFile(filePath string) (string, error) {
 	return string(content), nil
 }
 
-func chatWithModel(client openai.Client, ownerID, sessionID, model, system, user, debug string, jsonSchema *ext_openai.ChatCompletionResponseFormatJSONSchema) ([]types.DataPrepTextQuestionRaw, error) {
+func chatWithModel(client openai.Client, ownerID, sessionID, model, system, user, debug string, jsonSchema *ext_openai.ChatCompletionResponseFormatJSONSchema) ([]types.DataPrepTextQuestionRaw, ext_openai.Usage, error) {
 	req := ext_openai.ChatCompletionRequest{
 		Model: model,
 		Messages: []ext_openai.ChatCompletionMessage{
@@ -325,7 +505,7 @@ func chatWithModel(client openai.Client, ownerID, sessionID, model, system, user
 
 	resp, err := client.CreateChatCompletion(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("ChatCompletion error (%s): %v", debug, err)
+		return nil, ext_openai.Usage{}, fmt.Errorf("ChatCompletion error (%s): %v", debug, err)
 	}
 
 	answer := resp.Choices[0].Message.Content
@@ -336,7 +516,8 @@ func chatWithModel(client openai.Client, ownerID, sessionID, model, system, user
 		answer = tools.AttemptFixJSON(answer)
 	}
 
-	return TryVariousJSONFormats(answer, fmt.Sprintf("%s respID=%s", debug, resp.ID))
+	pairs, err := TryVariousJSONFormats(answer, fmt.Sprintf("%s respID=%s", debug, resp.ID))
+	return pairs, resp.Usage, err
 }
 
 // for prompt engineering purposes, the LLMs output various formats. Try all of them:
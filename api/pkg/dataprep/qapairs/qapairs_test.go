@@ -0,0 +1,60 @@
+package qapairs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/openai"
+
+	ext_openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// chdir runs the test in dir, Run writes its results under the working directory
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+}
+
+func TestRun_UsesConfigNumQuestions(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	configFile := filepath.Join(dir, "qapairs.yaml")
+	err := os.WriteFile(configFile, []byte(`
+num_questions: 3
+text_chunk_tokens: 1000
+prompts:
+  - name: count
+    system: "Write {{.NumQuestions}} questions."
+    user: "{{.DocumentChunk}}"
+texts:
+  - name: sharks
+    contents: Sharks have no bones.
+`), 0644)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	client := openai.NewMockClient(ctrl)
+
+	client.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req ext_openai.ChatCompletionRequest) (ext_openai.ChatCompletionResponse, error) {
+			require.Equal(t, "Write 3 questions.", req.Messages[0].Content)
+			return judgeResponse(`[{"question": "Do sharks have bones?", "answer": "No."}]`), nil
+		})
+
+	err = Run(client, RunOptions{
+		Model:      "llama3",
+		ConfigFile: configFile,
+		Prompts:    []string{"count"},
+		Texts:      []string{"sharks"},
+	})
+	require.NoError(t, err)
+}
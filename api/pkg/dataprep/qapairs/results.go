@@ -0,0 +1,219 @@
+package qapairs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/helixml/helix/api/pkg/types"
+
+	ext_openai "github.com/sashabaranov/go-openai"
+)
+
+// Result is the outcome of generating QA pairs for one prompt and text chunk
+type Result struct {
+	Target           string                          `json:"target"`
+	Prompt           string                          `json:"prompt"`
+	Text             string                          `json:"text"`
+	Chunk            int                             `json:"chunk"`
+	LatencyMs        int64                           `json:"latency_ms"`
+	PromptTokens     int                             `json:"prompt_tokens"`
+	CompletionTokens int                             `json:"completion_tokens"`
+	JSONMode         bool                            `json:"json_mode"` // retried with the JSON schema enforced
+	Parsed           bool                            `json:"parsed"`
+	Error            string                          `json:"error,omitempty"`
+	Pairs            []types.DataPrepTextQuestionRaw `json:"pairs"`
+	// Set when the judge pass ran, Pairs then only holds the accepted pairs
+	Rejected    int          `json:"rejected"`
+	JudgeTokens int          `json:"judge_tokens,omitempty"`
+	Judgements  []*Judgement `json:"judgements,omitempty"`
+}
+
+func (r *Result) addUsage(usage ext_openai.Usage) {
+	r.PromptTokens += usage.PromptTokens
+	r.CompletionTokens += usage.CompletionTokens
+}
+
+// TargetReport aggregates the results of a target (model) so runs can be
+// compared across model versions
+type TargetReport struct {
+	Target           string  `json:"target"`
+	Queries          int     `json:"queries"`
+	Parsed           int     `json:"parsed"`
+	ParseSuccessRate float64 `json:"parse_success_rate"`
+	LatencyP50Ms     int64   `json:"latency_p50_ms"`
+	LatencyP90Ms     int64   `json:"latency_p90_ms"`
+	LatencyP99Ms     int64   `json:"latency_p99_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Pairs            int     `json:"pairs"`
+	Rejected         int     `json:"rejected"`
+	AcceptanceRate   float64 `json:"acceptance_rate"`
+	JudgeTokens      int     `json:"judge_tokens"`
+}
+
+type Report struct {
+	Targets []*TargetReport `json:"targets"`
+}
+
+func NewReport(results []*Result) *Report {
+	byTarget := make(map[string][]*Result)
+	for _, result := range results {
+		byTarget[result.Target] = append(byTarget[result.Target], result)
+	}
+
+	report := &Report{Targets: []*TargetReport{}}
+	for target, targetResults := range byTarget {
+		tr := &TargetReport{
+			Target:  target,
+			Queries: len(targetResults),
+		}
+
+		latencies := make([]int64, 0, len(targetResults))
+		for _, result := range targetResults {
+			if result.Parsed {
+				tr.Parsed++
+			}
+			tr.PromptTokens += result.PromptTokens
+			tr.CompletionTokens += result.CompletionTokens
+			tr.Pairs += len(result.Pairs)
+			tr.Rejected += result.Rejected
+			tr.JudgeTokens += result.JudgeTokens
+			latencies = append(latencies, result.LatencyMs)
+		}
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		tr.LatencyP50Ms = percentile(latencies, 50)
+		tr.LatencyP90Ms = percentile(latencies, 90)
+		tr.LatencyP99Ms = percentile(latencies, 99)
+		tr.ParseSuccessRate = float64(tr.Parsed) / float64(tr.Queries)
+		if generated := tr.Pairs + tr.Rejected; generated > 0 {
+			tr.AcceptanceRate = float64(tr.Pairs) / float64(generated)
+		}
+
+		report.Targets = append(report.Targets, tr)
+	}
+
+	sort.Slice(report.Targets, func(i, j int) bool { return report.Targets[i].Target < report.Targets[j].Target })
+
+	return report
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// writeResults writes the results of the run as JSONL and CSV together with
+// the aggregate report into dir and prints the report
+func writeResults(out io.Writer, dir, runID string, results []*Result) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	prefix := filepath.Join(dir, runID)
+
+	if err := writeResultsJSONL(prefix+"_results.jsonl", results); err != nil {
+		return fmt.Errorf("failed to write results, error: %w", err)
+	}
+
+	if err := writeResultsCSV(prefix+"_results.csv", results); err != nil {
+		return fmt.Errorf("failed to write results, error: %w", err)
+	}
+
+	report := NewReport(results)
+
+	bts, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(prefix+"_report.json", bts, 0644); err != nil {
+		return fmt.Errorf("failed to write report, error: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tQUERIES\tPARSED\tP50\tP90\tP99\tPROMPT TOKENS\tCOMPLETION TOKENS\tPAIRS\tREJECTED")
+	for _, tr := range report.Targets {
+		fmt.Fprintf(w, "%s\t%d\t%.0f%%\t%dms\t%dms\t%dms\t%d\t%d\t%d\t%d\n",
+			tr.Target, tr.Queries, tr.ParseSuccessRate*100,
+			tr.LatencyP50Ms, tr.LatencyP90Ms, tr.LatencyP99Ms,
+			tr.PromptTokens, tr.CompletionTokens, tr.Pairs, tr.Rejected)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nResults written to %s_results.jsonl, %s_results.csv and %s_report.json\n", prefix, prefix, prefix)
+
+	return nil
+}
+
+func writeResultsJSONL(filename string, results []*Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, result := range results {
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func writeResultsCSV(filename string, results []*Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	err = w.Write([]string{"target", "prompt", "text", "chunk", "latency_ms", "prompt_tokens", "completion_tokens", "json_mode", "parsed", "pairs", "rejected", "error"})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		err := w.Write([]string{
+			r.Target,
+			r.Prompt,
+			r.Text,
+			strconv.Itoa(r.Chunk),
+			strconv.FormatInt(r.LatencyMs, 10),
+			strconv.Itoa(r.PromptTokens),
+			strconv.Itoa(r.CompletionTokens),
+			strconv.FormatBool(r.JSONMode),
+			strconv.FormatBool(r.Parsed),
+			strconv.Itoa(len(r.Pairs)),
+			strconv.Itoa(r.Rejected),
+			r.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
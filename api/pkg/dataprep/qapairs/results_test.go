@@ -0,0 +1,82 @@
+package qapairs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReport(t *testing.T) {
+	pairs := []types.DataPrepTextQuestionRaw{{Question: "q", Answer: "a"}, {Question: "q2", Answer: "a2"}}
+
+	var results []*Result
+	for i := int64(1); i <= 10; i++ {
+		results = append(results, &Result{
+			Target:           "llama3",
+			LatencyMs:        i * 100,
+			PromptTokens:     10,
+			CompletionTokens: 5,
+			Parsed:           i != 10,
+			Pairs:            pairs,
+			Rejected:         1,
+		})
+	}
+	results = append(results, &Result{Target: "gpt-4o", LatencyMs: 50, Parsed: true})
+
+	report := NewReport(results)
+	require.Len(t, report.Targets, 2)
+
+	require.Equal(t, "gpt-4o", report.Targets[0].Target)
+	require.Equal(t, int64(50), report.Targets[0].LatencyP99Ms)
+	require.Equal(t, 1.0, report.Targets[0].ParseSuccessRate)
+
+	llama := report.Targets[1]
+	require.Equal(t, 10, llama.Queries)
+	require.Equal(t, 9, llama.Parsed)
+	require.InDelta(t, 0.9, llama.ParseSuccessRate, 0.0001)
+	require.Equal(t, int64(500), llama.LatencyP50Ms)
+	require.Equal(t, int64(900), llama.LatencyP90Ms)
+	require.Equal(t, int64(1000), llama.LatencyP99Ms)
+	require.Equal(t, 100, llama.PromptTokens)
+	require.Equal(t, 50, llama.CompletionTokens)
+	require.Equal(t, 20, llama.Pairs)
+	require.Equal(t, 10, llama.Rejected)
+	require.InDelta(t, 2.0/3, llama.AcceptanceRate, 0.0001)
+}
+
+func TestWriteResults(t *testing.T) {
+	dir := t.TempDir()
+
+	results := []*Result{
+		{Target: "llama3", Prompt: "simple-quiz", Text: "sharks", LatencyMs: 120, Parsed: true, Pairs: []types.DataPrepTextQuestionRaw{{Question: "q", Answer: "a"}}},
+		{Target: "llama3", Prompt: "simple-quiz", Text: "taylor", LatencyMs: 300, JSONMode: true, Error: "error parsing JSON"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeResults(&out, dir, "1700000000", results))
+	require.Contains(t, out.String(), "llama3")
+	require.Contains(t, out.String(), "50%")
+
+	jsonl, err := os.ReadFile(filepath.Join(dir, "1700000000_results.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(jsonl)), "\n"), 2)
+
+	f, err := os.Open(filepath.Join(dir, "1700000000_results.csv"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, []string{"llama3", "simple-quiz", "taylor", "0", "300", "0", "0", "true", "false", "0", "0", "error parsing JSON"}, records[2])
+
+	_, err = os.Stat(filepath.Join(dir, "1700000000_report.json"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,183 @@
+package qapairs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TextDir discovers texts in directories instead of listing them one by one
+type TextDir struct {
+	// Directory, file or glob of them, relative to the config file
+	Path      string `yaml:"path"`
+	Recursive bool   `yaml:"recursive"`
+	// Files matching none of these are skipped, "text/*" matches any text
+	// type. Defaults to text/*
+	MimeTypes []string `yaml:"mime_types"`
+}
+
+var defaultTextMimeTypes = []string{"text/*"}
+
+// LoadConfig returns the built-in config overlaid with the config file at
+// path, settings missing from the file keep their built-in value. Relative
+// text paths are resolved against the directory of the file.
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	err := yaml.Unmarshal([]byte(qapairConfig), &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal qapair config: %v", err)
+	}
+
+	if path == "" {
+		return &config, nil
+	}
+
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read qapair config %s: %w", path, err)
+	}
+
+	err = yaml.Unmarshal(bts, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal qapair config %s: %v", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	for i := range config.Texts {
+		if config.Texts[i].File != "" && !filepath.IsAbs(config.Texts[i].File) {
+			config.Texts[i].File = filepath.Join(baseDir, config.Texts[i].File)
+		}
+	}
+	for i := range config.TextDirs {
+		if !filepath.IsAbs(config.TextDirs[i].Path) {
+			config.TextDirs[i].Path = filepath.Join(baseDir, config.TextDirs[i].Path)
+		}
+	}
+
+	return &config, nil
+}
+
+// discoverTexts returns a text for every file under the paths matching the
+// glob of dir, named by its path relative to the matched directory
+func discoverTexts(dir TextDir) ([]Text, error) {
+	matches, err := filepath.Glob(dir.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid texts path %s: %w", dir.Path, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no texts found at %s", dir.Path)
+	}
+
+	mimeTypes := dir.MimeTypes
+	if len(mimeTypes) == 0 {
+		mimeTypes = defaultTextMimeTypes
+	}
+
+	var texts []Text
+
+	add := func(path, name string) error {
+		ok, err := matchesMimeType(path, mimeTypes)
+		if err != nil {
+			return err
+		}
+		if ok {
+			texts = append(texts, Text{Name: filepath.ToSlash(name), File: path})
+		}
+		return nil
+	}
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			if err := add(match, filepath.Base(match)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != match && !dir.Recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			name, err := filepath.Rel(match, path)
+			if err != nil {
+				return err
+			}
+			return add(path, name)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover texts in %s: %w", match, err)
+		}
+	}
+
+	return texts, nil
+}
+
+func matchesMimeType(path string, mimeTypes []string) (bool, error) {
+	mimeType, err := detectMimeType(path)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range mimeTypes {
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok {
+			if strings.HasPrefix(mimeType, prefix+"/") {
+				return true, nil
+			}
+			continue
+		}
+		if mimeType == t {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// detectMimeType goes by the file extension and sniffs the contents when it
+// is unknown, e.g. for markdown on systems without a mime.types entry for it
+func detectMimeType(path string) (string, error) {
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+
+	if mimeType == "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		head := make([]byte, 512)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return "", err
+		}
+		mimeType = http.DetectContentType(head[:n])
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return "", fmt.Errorf("invalid mime type %s for %s: %w", mimeType, path, err)
+	}
+	return mediaType, nil
+}
@@ -0,0 +1,71 @@
+package qapairs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path string, contents []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.NoError(t, os.WriteFile(path, contents, 0644))
+}
+
+func TestLoadConfig(t *testing.T) {
+	builtin, err := LoadConfig("")
+	require.NoError(t, err)
+	require.NotEmpty(t, builtin.Prompts)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "qapairs.yaml"), []byte(`
+num_questions: 5
+texts:
+ - name: sharks
+   file: texts/sharks.txt
+text_dirs:
+ - path: corpus
+`))
+
+	config, err := LoadConfig(filepath.Join(dir, "qapairs.yaml"))
+	require.NoError(t, err)
+
+	require.Equal(t, 5, config.NumQuestions)
+	// Not in the file, keeps the built-in value
+	require.Equal(t, builtin.Prompts, config.Prompts)
+	require.Equal(t, builtin.TextChunkTokens, config.TextChunkTokens)
+
+	require.Equal(t, filepath.Join(dir, "texts", "sharks.txt"), config.Texts[0].File)
+	require.Equal(t, filepath.Join(dir, "corpus"), config.TextDirs[0].Path)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestDiscoverTexts(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "docs", "intro.txt"), []byte("Welcome"))
+	writeTestFile(t, filepath.Join(dir, "docs", "guide", "setup.md"), []byte("# Setup\n\nRun the installer."))
+	writeTestFile(t, filepath.Join(dir, "docs", "guide", "config.json"), []byte(`{"key": "value"}`))
+	writeTestFile(t, filepath.Join(dir, "docs", "logo.png"), []byte("\x89PNG\r\n\x1a\n"))
+	writeTestFile(t, filepath.Join(dir, "docs", "blob"), []byte{0x00, 0x01, 0x02, 0xff})
+
+	texts, err := discoverTexts(TextDir{Path: filepath.Join(dir, "docs"), Recursive: true})
+	require.NoError(t, err)
+	require.Equal(t, []Text{
+		{Name: "guide/setup.md", File: filepath.Join(dir, "docs", "guide", "setup.md")},
+		{Name: "intro.txt", File: filepath.Join(dir, "docs", "intro.txt")},
+	}, texts)
+
+	texts, err = discoverTexts(TextDir{Path: filepath.Join(dir, "docs")})
+	require.NoError(t, err)
+	require.Equal(t, []Text{{Name: "intro.txt", File: filepath.Join(dir, "docs", "intro.txt")}}, texts)
+
+	texts, err = discoverTexts(TextDir{Path: filepath.Join(dir, "docs", "guide", "*"), MimeTypes: []string{"application/json"}})
+	require.NoError(t, err)
+	require.Equal(t, []Text{{Name: "config.json", File: filepath.Join(dir, "docs", "guide", "config.json")}}, texts)
+
+	_, err = discoverTexts(TextDir{Path: filepath.Join(dir, "nope")})
+	require.Error(t, err)
+}
@@ -0,0 +1,261 @@
+package filestore
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Options configures an S3 (or S3 compatible) bucket. When no access key is
+// given the credentials are taken from the AWS environment variables, the
+// shared credentials file or the instance role.
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Insecure connects to the endpoint over plain HTTP, for local MinIO
+	Insecure bool
+}
+
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = "s3.amazonaws.com"
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{},
+	})
+	if opts.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, "")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !opts.Insecure,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &S3Storage{
+		client: client,
+		bucket: opts.Bucket,
+	}, nil
+}
+
+func (s *S3Storage) item(info minio.ObjectInfo) Item {
+	return Item{
+		Directory: strings.HasSuffix(info.Key, "/"),
+		Name:      info.Key,
+		Path:      info.Key,
+		URL:       s.client.EndpointURL().JoinPath(s.bucket, info.Key).String(),
+		Created:   info.LastModified.Unix(),
+		Size:      info.Size,
+	}
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]Item, error) {
+	items := []Item{}
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("error listing S3 objects: %w", info.Err)
+		}
+		items = append(items, s.item(info))
+	}
+	return items, nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, path string) (Item, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return Item{}, fmt.Errorf("S3 object %s: %w", path, os.ErrNotExist)
+		}
+		return Item{}, fmt.Errorf("error fetching S3 object attributes: %w", err)
+	}
+	return s.item(info), nil
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, path string) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, path, 20*time.Minute, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign S3 URL: %w", err)
+	}
+	return u.String(), nil
+}
+
+func (s *S3Storage) WriteFile(ctx context.Context, path string, r io.Reader) (Item, error) {
+	if _, err := s.client.PutObject(ctx, s.bucket, path, r, -1, minio.PutObjectOptions{}); err != nil {
+		return Item{}, fmt.Errorf("failed to upload S3 object: %w", err)
+	}
+	return s.Get(ctx, path)
+}
+
+func (s *S3Storage) OpenFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, path, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 object reader: %w", err)
+	}
+	// GetObject is lazy, stat it so a missing object fails here
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("failed to open S3 object: %w", err)
+	}
+	return obj, nil
+}
+
+func (s *S3Storage) DownloadFolder(ctx context.Context, path string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	defer tarWriter.Close()
+
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: path, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+
+		reader, err := s.OpenFile(ctx, info.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name: info.Key,
+			Mode: 0600,
+			Size: info.Size,
+		}); err != nil {
+			reader.Close()
+			return nil, err
+		}
+
+		if _, err := io.Copy(tarWriter, reader); err != nil {
+			reader.Close()
+			return nil, err
+		}
+		reader.Close()
+	}
+
+	return &buf, nil
+}
+
+func (s *S3Storage) UploadFolder(ctx context.Context, path string, r io.Reader) error {
+	tarReader := tar.NewReader(r)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar header: %w", err)
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		objPath := path + "/" + header.Name
+		if _, err := s.client.PutObject(ctx, s.bucket, objPath, tarReader, header.Size, minio.PutObjectOptions{}); err != nil {
+			return fmt.Errorf("failed to upload S3 object: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *S3Storage) copy(ctx context.Context, fromPath, toPath string) error {
+	_, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: toPath},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: fromPath},
+	)
+	return err
+}
+
+func (s *S3Storage) Rename(ctx context.Context, path string, newPath string) (Item, error) {
+	if strings.HasSuffix(path, "/") {
+		for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: path, Recursive: true}) {
+			if info.Err != nil {
+				return Item{}, fmt.Errorf("error iterating over S3 objects during rename: %w", info.Err)
+			}
+			if err := s.copy(ctx, info.Key, strings.Replace(info.Key, path, newPath, 1)); err != nil {
+				return Item{}, fmt.Errorf("error copying S3 object during rename: %w", err)
+			}
+			if err := s.client.RemoveObject(ctx, s.bucket, info.Key, minio.RemoveObjectOptions{}); err != nil {
+				return Item{}, fmt.Errorf("error deleting original S3 object post rename: %w", err)
+			}
+		}
+	} else {
+		if err := s.copy(ctx, path, newPath); err != nil {
+			return Item{}, fmt.Errorf("failed to rename S3 object: %w", err)
+		}
+		if err := s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{}); err != nil {
+			return Item{}, fmt.Errorf("failed to delete original S3 object after renaming: %w", err)
+		}
+	}
+	return s.Get(ctx, newPath)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	if strings.HasSuffix(path, "/") {
+		for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: path, Recursive: true}) {
+			if info.Err != nil {
+				return fmt.Errorf("error iterating over S3 objects during delete: %w", info.Err)
+			}
+			if err := s.client.RemoveObject(ctx, s.bucket, info.Key, minio.RemoveObjectOptions{}); err != nil {
+				return fmt.Errorf("error deleting S3 object: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete S3 object: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) CreateFolder(ctx context.Context, path string) (Item, error) {
+	folder := strings.TrimSuffix(path, "/") + "/"
+	if _, err := s.client.PutObject(ctx, s.bucket, folder, bytes.NewReader(nil), 0, minio.PutObjectOptions{}); err != nil {
+		return Item{}, fmt.Errorf("failed to create S3 folder: %w", err)
+	}
+	return s.Get(ctx, folder)
+}
+
+func (s *S3Storage) CopyFile(ctx context.Context, fromPath string, toPath string) error {
+	if _, err := s.Get(ctx, fromPath); err != nil {
+		return fmt.Errorf("failed to get source file: %w", err)
+	}
+
+	// folders are only key prefixes in S3, create the marker for listings
+	if _, err := s.CreateFolder(ctx, filepath.Dir(toPath)); err != nil {
+		return fmt.Errorf("failed to create destination folder: %w", err)
+	}
+
+	if err := s.copy(ctx, fromPath, toPath); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	return nil
+}
+
+// Compile-time interface check:
+var _ FileStore = (*S3Storage)(nil)
@@ -2,11 +2,14 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/helixml/helix/api/pkg/auth"
 	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/types"
 	"github.com/rs/zerolog/log"
 )
@@ -14,7 +17,8 @@ import (
 type Provider string
 
 const (
-	ProviderEmail Provider = "email"
+	ProviderEmail   Provider = "email"
+	ProviderWebPush Provider = "web_push"
 )
 
 type Event int
@@ -22,14 +26,28 @@ type Event int
 const (
 	EventFinetuningStarted  Event = 1
 	EventFinetuningComplete Event = 2
+	EventSessionComplete    Event = 3
+	EventApprovalRequired   Event = 4
 )
 
+// Events are the events users can opt out of in their notification preferences
+var Events = []Event{
+	EventFinetuningStarted,
+	EventFinetuningComplete,
+	EventSessionComplete,
+	EventApprovalRequired,
+}
+
 func (e Event) String() string {
 	switch e {
 	case EventFinetuningStarted:
 		return "finetuning_started"
 	case EventFinetuningComplete:
 		return "finetuning_complete"
+	case EventSessionComplete:
+		return "session_complete"
+	case EventApprovalRequired:
+		return "approval_required"
 	default:
 		return "unknown_event"
 	}
@@ -50,11 +68,13 @@ type Notifier interface {
 
 type NotificationsProvider struct {
 	authenticator auth.Authenticator
+	store         store.Store
 
-	email *Email
+	email   *Email
+	webPush *WebPush
 }
 
-func New(cfg *config.Notifications, authenticator auth.Authenticator) (Notifier, error) {
+func New(cfg *config.Notifications, authenticator auth.Authenticator, store store.Store, webPush *WebPush) (Notifier, error) {
 	email, err := NewEmail(cfg)
 	if err != nil {
 		return nil, err
@@ -62,24 +82,55 @@ func New(cfg *config.Notifications, authenticator auth.Authenticator) (Notifier,
 
 	return &NotificationsProvider{
 		authenticator: authenticator,
+		store:         store,
 		email:         email,
+		webPush:       webPush,
 	}, nil
 }
 
 func (n *NotificationsProvider) Notify(ctx context.Context, notification *Notification) error {
-	user, err := n.authenticator.GetUserByID(ctx, notification.Session.Owner)
-	if err != nil {
-		return fmt.Errorf("failed to get user '%s' details: %w", notification.Session.Owner, err)
+	// Every response completes a session, that's too often for email
+	sendEmail := n.email.Enabled() && notification.Event != EventSessionComplete
+	sendWebPush := n.webPush != nil && n.webPush.Enabled()
+
+	if !sendEmail && !sendWebPush {
+		return nil
+	}
+
+	prefs, err := n.store.GetNotificationPreferences(ctx, notification.Session.Owner)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		// nothing has been turned off
+	case err != nil:
+		return fmt.Errorf("failed to get notification preferences for '%s': %w", notification.Session.Owner, err)
+	default:
+		event := notification.Event.String()
+		sendEmail = sendEmail && !slices.Contains(prefs.DisabledEmailEvents, event)
+		sendWebPush = sendWebPush && !slices.Contains(prefs.DisabledWebPushEvents, event)
 	}
 
 	log.Debug().
-		Str("email", user.Email).Str("notification", notification.Event.String()).Msg("sending notification")
+		Str("owner", notification.Session.Owner).Str("notification", notification.Event.String()).Msg("sending notification")
 
-	notification.Email = user.Email
-	notification.FirstName = strings.Split(user.FullName, " ")[0]
+	// only email needs the user's details, don't ask the identity provider
+	// otherwise
+	if sendEmail {
+		user, err := n.authenticator.GetUserByID(ctx, notification.Session.Owner)
+		if err != nil {
+			return fmt.Errorf("failed to get user '%s' details: %w", notification.Session.Owner, err)
+		}
+
+		notification.Email = user.Email
+		notification.FirstName = strings.Split(user.FullName, " ")[0]
+
+		err = n.email.Notify(ctx, notification)
+		if err != nil {
+			return err
+		}
+	}
 
-	if n.email.Enabled() {
-		err := n.email.Notify(ctx, notification)
+	if sendWebPush {
+		err := n.webPush.Notify(ctx, notification)
 		if err != nil {
 			return err
 		}
@@ -114,6 +114,19 @@ func (e *Email) getEmailMessage(n *Notification) (title, message string, err err
 		}
 
 		return fmt.Sprintf("Finetuning Complete - Ready for Action [%s]", n.Session.Name), buf.String(), nil
+	case EventApprovalRequired:
+		var buf bytes.Buffer
+
+		err = approvalRequiredTmpl.Execute(&buf, &templateData{
+			FirstName:   n.FirstName,
+			SessionURL:  fmt.Sprintf("%s/session/%s", e.cfg.AppURL, n.Session.ID),
+			SessionName: n.Session.Name,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to execute template: %w", err)
+		}
+
+		return fmt.Sprintf("Your Review Is Needed [%s]", n.Session.Name), buf.String(), nil
 	default:
 		return "", "", fmt.Errorf("unknown event '%s'", n.Event.String())
 	}
@@ -128,6 +141,7 @@ type templateData struct {
 var (
 	finetuningStartedTmpl   = template.Must(template.New("").Parse(finetuningStartedTemplate))
 	finetuningCompletedTmpl = template.Must(template.New("").Parse(finetuningCompletedTemplate))
+	approvalRequiredTmpl    = template.Must(template.New("").Parse(approvalRequiredTemplate))
 )
 
 var finetuningStartedTemplate = `
@@ -155,3 +169,14 @@ We hope you enjoy the improvements. If you have any feedback or require assistan
 Best regards,<br/><br/>
 The Helix Team
 `
+
+var approvalRequiredTemplate = `
+Dear {{ .FirstName }},
+<br/><br/>
+Your session '{{ .SessionName }}' is waiting for you. The training data has been prepared and needs your review before finetuning can continue.
+<br/><br/>
+To review and approve it, please visit: <a href="{{ .SessionURL }}" target="_blank">{{ .SessionURL }}</a>.
+<br/><br/>
+Best regards,<br/><br/>
+The Helix Team
+`
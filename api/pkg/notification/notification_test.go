@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/auth"
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type countingAuthenticator struct {
+	auth.Authenticator
+	calls int
+}
+
+func (a *countingAuthenticator) GetUserByID(_ context.Context, _ string) (*types.User, error) {
+	a.calls++
+	return &types.User{Email: "foo@example.com", FullName: "Foo Bar"}, nil
+}
+
+func TestNotify_SkipsUserLookupWithoutEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   *config.Notifications
+		event Event
+	}{
+		{
+			name:  "no providers enabled",
+			cfg:   &config.Notifications{},
+			event: EventFinetuningComplete,
+		},
+		{
+			name: "email is not sent for completed sessions",
+			cfg: func() *config.Notifications {
+				cfg := &config.Notifications{}
+				cfg.Email.Mailgun.APIKey = "key"
+				return cfg
+			}(),
+			event: EventSessionComplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &countingAuthenticator{}
+
+			webPush, err := NewWebPush(tt.cfg, nil)
+			require.NoError(t, err)
+
+			notifier, err := New(tt.cfg, authenticator, nil, webPush)
+			require.NoError(t, err)
+
+			err = notifier.Notify(context.Background(), &Notification{
+				Event:   tt.event,
+				Session: &types.Session{ID: "ses_1", Owner: "user-1"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 0, authenticator.calls)
+		})
+	}
+}
+
+func TestNotify_RespectsPreferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefs      *types.NotificationPreferences
+		prefsErr   error
+		wantQueued int
+	}{
+		{
+			name:       "no preferences saved",
+			prefsErr:   store.ErrNotFound,
+			wantQueued: 1,
+		},
+		{
+			name:       "other event disabled",
+			prefs:      &types.NotificationPreferences{DisabledWebPushEvents: types.StringArray{"finetuning_started"}},
+			wantQueued: 1,
+		},
+		{
+			name:  "event disabled",
+			prefs: &types.NotificationPreferences{DisabledWebPushEvents: types.StringArray{"approval_required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storeMock := store.NewMockStore(ctrl)
+			storeMock.EXPECT().GetNotificationPreferences(gomock.Any(), "user-1").Return(tt.prefs, tt.prefsErr)
+
+			cfg := &config.Notifications{}
+
+			// no worker, so queued messages stay in the queue
+			webPush := &WebPush{cfg: cfg, privateKey: "key", queue: make(chan *webPushMessage, 1)}
+
+			notifier, err := New(cfg, &countingAuthenticator{}, storeMock, webPush)
+			require.NoError(t, err)
+
+			err = notifier.Notify(context.Background(), &Notification{
+				Event:   EventApprovalRequired,
+				Session: &types.Session{ID: "ses_1", Owner: "user-1", Name: "Support bot"},
+			})
+			require.NoError(t, err)
+			require.Len(t, webPush.queue, tt.wantQueued)
+		})
+	}
+}
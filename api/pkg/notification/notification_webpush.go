@@ -0,0 +1,257 @@
+package notification
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	webPushTTL       = 24 * time.Hour
+	webPushQueueSize = 100
+)
+
+// WebPush delivers notifications to the browsers the session owner has
+// subscribed. webpush-go takes care of VAPID (RFC 8292) and the aes128gcm
+// payload encryption (RFC 8291). Messages are delivered by a background
+// worker so slow push services don't hold up the caller.
+type WebPush struct {
+	cfg        *config.Notifications
+	store      store.Store
+	httpClient *http.Client
+
+	// base64url encoded VAPID keys
+	privateKey string
+	publicKey  string
+
+	queue chan *webPushMessage
+}
+
+type webPushMessage struct {
+	owner   string
+	payload []byte
+}
+
+// webPushPayload is what the service worker receives in the push event
+type webPushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag"`
+}
+
+func NewWebPush(cfg *config.Notifications, store store.Store) (*WebPush, error) {
+	w := &WebPush{
+		cfg:        cfg,
+		store:      store,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if cfg.WebPush.VAPIDPrivateKey == "" {
+		return w, nil
+	}
+
+	publicKey, err := VAPIDPublicKey(cfg.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	w.privateKey = cfg.WebPush.VAPIDPrivateKey
+	w.publicKey = publicKey
+	w.queue = make(chan *webPushMessage, webPushQueueSize)
+
+	go w.runWorker()
+
+	return w, nil
+}
+
+func (w *WebPush) Enabled() bool {
+	return w.privateKey != ""
+}
+
+func (w *WebPush) Notify(ctx context.Context, n *Notification) error {
+	title, body, err := w.getMessage(n)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&webPushPayload{
+		Title: title,
+		Body:  body,
+		URL:   fmt.Sprintf("%s/session/%s", w.cfg.AppURL, n.Session.ID),
+		Tag:   n.Session.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case w.queue <- &webPushMessage{owner: n.Session.Owner, payload: payload}:
+	default:
+		log.Ctx(ctx).Warn().Str("session_id", n.Session.ID).Msg("web push queue is full, dropping notification")
+	}
+
+	return nil
+}
+
+func (w *WebPush) getMessage(n *Notification) (title, body string, err error) {
+	switch n.Event {
+	case EventFinetuningStarted:
+		return "Finetuning started", fmt.Sprintf("Finetuning of '%s' has begun, we'll let you know when it's done.", n.Session.Name), nil
+	case EventFinetuningComplete:
+		return "Finetuning complete", fmt.Sprintf("'%s' is ready to use.", n.Session.Name), nil
+	case EventSessionComplete:
+		if n.Session.Name == "" {
+			return "Response ready", "Your session has a new response.", nil
+		}
+		return "Response ready", fmt.Sprintf("'%s' has a new response.", n.Session.Name), nil
+	case EventApprovalRequired:
+		return "Review needed", fmt.Sprintf("'%s' is waiting for your approval to continue.", n.Session.Name), nil
+	default:
+		return "", "", fmt.Errorf("unknown event '%s'", n.Event.String())
+	}
+}
+
+// webPushHosts are the push services of Chrome/Edge, Firefox, Windows and
+// Safari. Subscriptions are only accepted for these so the API can't be made
+// to POST to arbitrary (e.g. internal) addresses.
+var webPushHosts = []string{
+	"fcm.googleapis.com",
+	"updates.push.services.mozilla.com",
+	"*.notify.windows.com",
+	"web.push.apple.com",
+}
+
+// ValidatePushEndpoint checks that the subscription endpoint is an https URL
+// of a known push service
+func ValidatePushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("endpoint must be an https URL")
+	}
+	if u.Port() != "" && u.Port() != "443" {
+		return fmt.Errorf("endpoint must use the default https port")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range webPushHosts {
+		if wildcard, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, wildcard) {
+				return nil
+			}
+			continue
+		}
+		if host == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("endpoint host '%s' is not a known push service", host)
+}
+
+func (w *WebPush) runWorker() {
+	for msg := range w.queue {
+		w.deliver(context.Background(), msg)
+	}
+}
+
+// deliver sends the message to every subscription of the owner, removing
+// subscriptions the push service reports as expired
+func (w *WebPush) deliver(ctx context.Context, msg *webPushMessage) {
+	subs, err := w.store.ListPushSubscriptions(ctx, msg.owner)
+	if err != nil {
+		log.Error().Err(err).Str("owner", msg.owner).Msg("failed to list push subscriptions")
+		return
+	}
+
+	for _, sub := range subs {
+		err := w.send(ctx, sub, msg.payload)
+		if errors.Is(err, errSubscriptionGone) {
+			log.Debug().Str("subscription_id", sub.ID).Msg("removing expired push subscription")
+
+			if err := w.store.DeletePushSubscription(ctx, sub.ID); err != nil {
+				log.Error().Err(err).Str("subscription_id", sub.ID).Msg("failed to delete push subscription")
+			}
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("subscription_id", sub.ID).Msg("failed to send push notification")
+		}
+	}
+}
+
+var errSubscriptionGone = errors.New("push subscription is no longer valid")
+
+func (w *WebPush) send(ctx context.Context, sub *types.PushSubscription, payload []byte) error {
+	// the library adds mailto: unless the subject is an https URL
+	subject := w.cfg.WebPush.Subject
+	if subject == "" {
+		subject = w.cfg.AppURL
+	}
+	subject = strings.TrimPrefix(subject, "mailto:")
+
+	resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys: webpush.Keys{
+			P256dh: sub.P256dh,
+			Auth:   sub.Auth,
+		},
+	}, &webpush.Options{
+		HTTPClient:      w.httpClient,
+		Subscriber:      subject,
+		TTL:             int(webPushTTL.Seconds()),
+		VAPIDPublicKey:  w.publicKey,
+		VAPIDPrivateKey: w.privateKey,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode >= 300:
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push service responded with %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// VAPIDPublicKey returns the base64url encoded application server key for the
+// private key, browsers pass it to pushManager.subscribe()
+func VAPIDPublicKey(privateKey string) (string, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	// Uncompressed point, 0x04 || X || Y
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// decodeBase64URL accepts both padded and unpadded base64url, browsers and
+// key generators differ
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,265 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/hkdf"
+)
+
+type testBrowser struct {
+	privateKey *ecdh.PrivateKey
+	authSecret []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	privateKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	authSecret := make([]byte, 16)
+	_, err = rand.Read(authSecret)
+	require.NoError(t, err)
+
+	return &testBrowser{privateKey: privateKey, authSecret: authSecret}
+}
+
+func (b *testBrowser) subscription(endpoint string) *types.PushSubscription {
+	return &types.PushSubscription{
+		ID:       "push_1",
+		Owner:    "user-1",
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.privateKey.PublicKey().Bytes()),
+		Auth:     base64.URLEncoding.EncodeToString(b.authSecret),
+	}
+}
+
+// decrypt decrypts an aes128gcm push message the way a browser would, see
+// RFC 8291 section 3
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	return decryptWebPushMessage(t, b.privateKey, b.authSecret, body)
+}
+
+func decryptWebPushMessage(t *testing.T, uaPrivate *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	salt := body[:16]
+	recordSize := binary.BigEndian.Uint32(body[16:20])
+	keyIDLen := int(body[20])
+	asPublicBytes := body[21 : 21+keyIDLen]
+	ciphertext := body[21+keyIDLen:]
+	require.LessOrEqual(t, len(body), int(recordSize))
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+	sharedSecret, err := uaPrivate.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm := hkdfBytes(t, sharedSecret, authSecret, keyInfo, 32)
+	cek := hkdfBytes(t, ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfBytes(t, ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+
+	// the record is padded with zeros after the 0x02 delimiter
+	plaintext = bytes.TrimRight(plaintext, "\x00")
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+
+	return plaintext[:len(plaintext)-1]
+}
+
+func hkdfBytes(t *testing.T, secret, salt, info []byte, length int) []byte {
+	out := make([]byte, length)
+	_, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+	require.NoError(t, err)
+	return out
+}
+
+// TestDecryptWebPushMessage_RFC8291 checks the decryption the other tests rely
+// on against the example in RFC 8291 appendix A
+func TestDecryptWebPushMessage_RFC8291(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	uaPrivate, err := ecdh.P256().NewPrivateKey(decode("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	require.NoError(t, err)
+	require.Equal(t,
+		"BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()))
+
+	body := decode("DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+
+	plaintext := decryptWebPushMessage(t, uaPrivate, decode("BTBZMqHH6r4Tts7J_aSIgg"), body)
+	require.Equal(t, "When I grow up, I want to be a watermelon", string(plaintext))
+}
+
+func newTestVAPIDKey(t *testing.T) string {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(key.Bytes())
+}
+
+func TestNewWebPush_Disabled(t *testing.T) {
+	w, err := NewWebPush(&config.Notifications{}, nil)
+	require.NoError(t, err)
+	require.False(t, w.Enabled())
+
+	_, err = NewWebPush(&config.Notifications{WebPush: config.WebPushConfig{VAPIDPrivateKey: "not-a-key"}}, nil)
+	require.Error(t, err)
+}
+
+func TestWebPush_Send(t *testing.T) {
+	browser := newTestBrowser(t)
+	vapidKey := newTestVAPIDKey(t)
+
+	var (
+		authorization string
+		body          []byte
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		require.Equal(t, "86400", r.Header.Get("TTL"))
+
+		authorization = r.Header.Get("Authorization")
+
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebPush(&config.Notifications{
+		AppURL:  "https://app.example.com",
+		WebPush: config.WebPushConfig{VAPIDPrivateKey: vapidKey, Subject: "mailto:ops@example.com"},
+	}, nil)
+	require.NoError(t, err)
+	w.httpClient = srv.Client()
+
+	require.NoError(t, w.send(context.Background(), browser.subscription(srv.URL+"/push/abc"), []byte(`{"title":"hello"}`)))
+
+	require.Equal(t, `{"title":"hello"}`, string(browser.decrypt(t, body)))
+
+	// vapid t=<jwt>, k=<public key>
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	parts := strings.SplitN(strings.TrimPrefix(authorization, "vapid t="), ", k=", 2)
+	require.Len(t, parts, 2)
+
+	publicKey, err := VAPIDPublicKey(vapidKey)
+	require.NoError(t, err)
+	require.Equal(t, publicKey, parts[1])
+
+	// uncompressed point, 0x04 || X || Y
+	point, err := base64.RawURLEncoding.DecodeString(publicKey)
+	require.NoError(t, err)
+	verifyKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(point[1:33]),
+		Y:     new(big.Int).SetBytes(point[33:]),
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(parts[0], claims, func(_ *jwt.Token) (interface{}, error) {
+		return verifyKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	require.Equal(t, srv.URL, claims["aud"])
+	require.Equal(t, "mailto:ops@example.com", claims["sub"])
+}
+
+func TestWebPush_DeliverRemovesExpiredSubscriptions(t *testing.T) {
+	browser := newTestBrowser(t)
+
+	gone := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer gone.Close()
+
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	w, err := NewWebPush(&config.Notifications{
+		WebPush: config.WebPushConfig{VAPIDPrivateKey: newTestVAPIDKey(t)},
+	}, storeMock)
+	require.NoError(t, err)
+	w.httpClient = gone.Client()
+
+	storeMock.EXPECT().ListPushSubscriptions(gomock.Any(), "user-1").Return([]*types.PushSubscription{
+		browser.subscription(gone.URL),
+	}, nil)
+	storeMock.EXPECT().DeletePushSubscription(gomock.Any(), "push_1").Return(nil)
+
+	payload, err := json.Marshal(&webPushPayload{Title: "Finetuning complete"})
+	require.NoError(t, err)
+
+	w.deliver(context.Background(), &webPushMessage{owner: "user-1", payload: payload})
+}
+
+func TestValidatePushEndpoint(t *testing.T) {
+	valid := []string{
+		"https://fcm.googleapis.com/fcm/send/abc:def",
+		"https://updates.push.services.mozilla.com/wpush/v2/gAAAA",
+		"https://wns2-par02p.notify.windows.com/w/?token=abc",
+		"https://web.push.apple.com/QGx2",
+	}
+	for _, endpoint := range valid {
+		require.NoError(t, ValidatePushEndpoint(endpoint), endpoint)
+	}
+
+	invalid := []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://127.0.0.1/push",
+		"https://169.254.169.254/latest/meta-data",
+		"https://localhost/push",
+		"https://fcm.googleapis.com.evil.example/push",
+		"https://evilnotify.windows.com/push",
+		"https://fcm.googleapis.com:8443/fcm/send/abc",
+		"not a url",
+	}
+	for _, endpoint := range invalid {
+		require.Error(t, ValidatePushEndpoint(endpoint), endpoint)
+	}
+}
+
+func TestWebPush_SessionCompleteMessage(t *testing.T) {
+	w := &WebPush{cfg: &config.Notifications{}}
+
+	title, body, err := w.getMessage(&Notification{
+		Event:   EventSessionComplete,
+		Session: &types.Session{ID: "ses_1", Name: "Refactor the parser"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Response ready", title)
+	require.Equal(t, "'Refactor the parser' has a new response.", body)
+}
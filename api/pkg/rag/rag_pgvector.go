@@ -0,0 +1,216 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var pgvectorTablePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Static check
+var _ RAG = &PGVector{}
+
+// PGVector stores chunks in a Postgres table with a pgvector embedding
+// column, the extension and table are created on first index
+type PGVector struct {
+	db       *sql.DB
+	table    string
+	embedder *embedder
+
+	mu         sync.Mutex
+	tableReady bool
+}
+
+func NewPGVector(connectionString, table string, embedder *embedder) (*PGVector, error) {
+	if table == "" {
+		table = defaultVectorStoreCollection
+	}
+
+	if !pgvectorTablePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid pgvector table name '%s', use lowercase letters, digits and underscores", table)
+	}
+
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pgvector database: %w", err)
+	}
+
+	return &PGVector{
+		db:       db,
+		table:    table,
+		embedder: embedder,
+	}, nil
+}
+
+// Close closes the connection pool to the database
+func (p *PGVector) Close() error {
+	return p.db.Close()
+}
+
+func (p *PGVector) Index(ctx context.Context, indexReqs ...*types.SessionRAGIndexChunk) error {
+	if len(indexReqs) == 0 {
+		return fmt.Errorf("no index requests provided")
+	}
+
+	embeddings, err := p.embedder.embed(ctx, chunkContents(indexReqs)...)
+	if err != nil {
+		return err
+	}
+
+	if err := p.ensureTable(ctx, len(embeddings[0])); err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (id, data_entity_id, document_id, document_group_id, filename, source, content_offset, content, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::vector)`, p.table))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, indexReq := range indexReqs {
+		_, err := stmt.ExecContext(ctx,
+			uuid.New().String(),
+			indexReq.DataEntityID,
+			indexReq.DocumentID,
+			indexReq.DocumentGroupID,
+			indexReq.Filename,
+			indexReq.Source,
+			indexReq.ContentOffset,
+			indexReq.Content,
+			vectorLiteral(embeddings[i]),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert chunk: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *PGVector) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+	if q.Prompt == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	embeddings, err := p.embedder.embed(ctx, q.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// <=> is the cosine distance operator
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, document_id, document_group_id, filename, source, content_offset, content, embedding <=> $1::vector AS distance
+		FROM %s
+		WHERE data_entity_id = $2
+		ORDER BY distance
+		LIMIT $3`, p.table),
+		vectorLiteral(embeddings[0]), q.DataEntityID, maxResults(q),
+	)
+	if isUndefinedTable(err) {
+		// Nothing indexed yet
+		return []*types.SessionRAGResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*types.SessionRAGResult{}
+	for rows.Next() {
+		var result types.SessionRAGResult
+		err := rows.Scan(
+			&result.ID,
+			&result.DocumentID,
+			&result.DocumentGroupID,
+			&result.Filename,
+			&result.Source,
+			&result.ContentOffset,
+			&result.Content,
+			&result.Distance,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+func (p *PGVector) Delete(ctx context.Context, r *types.DeleteIndexRequest) error {
+	if r.DataEntityID == "" {
+		return fmt.Errorf("data entity ID cannot be empty")
+	}
+
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE data_entity_id = $1`, p.table), r.DataEntityID)
+	if isUndefinedTable(err) {
+		return nil
+	}
+	return err
+}
+
+func (p *PGVector) ensureTable(ctx context.Context, dimensions int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tableReady {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id text PRIMARY KEY,
+			data_entity_id text NOT NULL,
+			document_id text NOT NULL DEFAULT '',
+			document_group_id text NOT NULL DEFAULT '',
+			filename text NOT NULL DEFAULT '',
+			source text NOT NULL DEFAULT '',
+			content_offset integer NOT NULL DEFAULT 0,
+			content text NOT NULL DEFAULT '',
+			embedding vector(%d) NOT NULL
+		)`, p.table, dimensions),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_data_entity_id_idx ON %s (data_entity_id)`, p.table, p.table),
+	}
+
+	for _, statement := range statements {
+		if _, err := p.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to prepare pgvector table %s: %w", p.table, err)
+		}
+	}
+
+	p.tableReady = true
+	return nil
+}
+
+func vectorLiteral(vector []float32) string {
+	values := make([]string, 0, len(vector))
+	for _, v := range vector {
+		values = append(values, strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
+func isUndefinedTable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+}
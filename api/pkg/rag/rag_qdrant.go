@@ -0,0 +1,200 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// Static check
+var _ RAG = &Qdrant{}
+
+// Qdrant stores chunks as points in a Qdrant collection using its REST API,
+// the collection is created with cosine distance on first index
+type Qdrant struct {
+	url        string
+	apiKey     string
+	collection string
+	embedder   *embedder
+	httpClient *http.Client
+
+	mu              sync.Mutex
+	collectionReady bool
+}
+
+func NewQdrant(baseURL, apiKey, collection string, embedder *embedder) *Qdrant {
+	if collection == "" {
+		collection = defaultVectorStoreCollection
+	}
+
+	return &Qdrant{
+		url:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		collection: collection,
+		embedder:   embedder,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type qdrantPoint struct {
+	ID      string                      `json:"id"`
+	Vector  []float32                   `json:"vector"`
+	Payload *types.SessionRAGIndexChunk `json:"payload"`
+}
+
+type qdrantFilter struct {
+	Must []qdrantCondition `json:"must"`
+}
+
+type qdrantCondition struct {
+	Key   string `json:"key"`
+	Match struct {
+		Value string `json:"value"`
+	} `json:"match"`
+}
+
+func dataEntityFilter(dataEntityID string) *qdrantFilter {
+	condition := qdrantCondition{Key: "data_entity_id"}
+	condition.Match.Value = dataEntityID
+	return &qdrantFilter{Must: []qdrantCondition{condition}}
+}
+
+func (q *Qdrant) Index(ctx context.Context, indexReqs ...*types.SessionRAGIndexChunk) error {
+	if len(indexReqs) == 0 {
+		return fmt.Errorf("no index requests provided")
+	}
+
+	embeddings, err := q.embedder.embed(ctx, chunkContents(indexReqs)...)
+	if err != nil {
+		return err
+	}
+
+	if err := q.ensureCollection(ctx, len(embeddings[0])); err != nil {
+		return err
+	}
+
+	points := make([]qdrantPoint, 0, len(indexReqs))
+	for i, indexReq := range indexReqs {
+		points = append(points, qdrantPoint{
+			ID:      uuid.New().String(),
+			Vector:  embeddings[i],
+			Payload: indexReq,
+		})
+	}
+
+	return q.do(ctx, http.MethodPut, "/points?wait=true", map[string]interface{}{"points": points}, nil)
+}
+
+func (q *Qdrant) Query(ctx context.Context, query *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+	if query.Prompt == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	embeddings, err := q.embedder.embed(ctx, query.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result []struct {
+			ID      string                     `json:"id"`
+			Score   float64                    `json:"score"`
+			Payload types.SessionRAGIndexChunk `json:"payload"`
+		} `json:"result"`
+	}
+
+	err = q.do(ctx, http.MethodPost, "/points/search", map[string]interface{}{
+		"vector":       embeddings[0],
+		"filter":       dataEntityFilter(query.DataEntityID),
+		"limit":        maxResults(query),
+		"with_payload": true,
+	}, &resp)
+	if err != nil {
+		if isHTTPNotFound(err) {
+			// Nothing indexed yet
+			return []*types.SessionRAGResult{}, nil
+		}
+		return nil, err
+	}
+
+	results := make([]*types.SessionRAGResult, 0, len(resp.Result))
+	for _, point := range resp.Result {
+		results = append(results, &types.SessionRAGResult{
+			ID:              point.ID,
+			DocumentID:      point.Payload.DocumentID,
+			DocumentGroupID: point.Payload.DocumentGroupID,
+			Filename:        point.Payload.Filename,
+			Source:          point.Payload.Source,
+			ContentOffset:   point.Payload.ContentOffset,
+			Content:         point.Payload.Content,
+			// Cosine similarity to distance
+			Distance: 1 - point.Score,
+		})
+	}
+
+	return results, nil
+}
+
+func (q *Qdrant) Delete(ctx context.Context, r *types.DeleteIndexRequest) error {
+	if r.DataEntityID == "" {
+		return fmt.Errorf("data entity ID cannot be empty")
+	}
+
+	err := q.do(ctx, http.MethodPost, "/points/delete?wait=true", map[string]interface{}{
+		"filter": dataEntityFilter(r.DataEntityID),
+	}, nil)
+	if isHTTPNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (q *Qdrant) ensureCollection(ctx context.Context, dimensions int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.collectionReady {
+		return nil
+	}
+
+	err := q.do(ctx, http.MethodGet, "", nil, nil)
+	if isHTTPNotFound(err) {
+		err = q.do(ctx, http.MethodPut, "", map[string]interface{}{
+			"vectors": map[string]interface{}{
+				"size":     dimensions,
+				"distance": "Cosine",
+			},
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create qdrant collection %s: %w", q.collection, err)
+		}
+
+		err = q.do(ctx, http.MethodPut, "/index?wait=true", map[string]interface{}{
+			"field_name":   "data_entity_id",
+			"field_schema": "keyword",
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	q.collectionReady = true
+	return nil
+}
+
+func (q *Qdrant) do(ctx context.Context, method, path string, body interface{}, v interface{}) error {
+	header := http.Header{}
+	if q.apiKey != "" {
+		header.Set("api-key", q.apiKey)
+	}
+
+	reqURL := q.url + "/collections/" + url.PathEscape(q.collection) + path
+	return doJSON(ctx, q.httpClient, method, reqURL, header, body, v)
+}
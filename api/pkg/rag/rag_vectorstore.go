@@ -0,0 +1,405 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/drone/envsubst"
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const defaultVectorStoreCollection = "helix_documents"
+
+// NewVectorStore creates a RAG client that indexes into a customer managed
+// vector store. Secret references must already be resolved.
+func NewVectorStore(settings *types.VectorStoreSettings) (RAG, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("vector store url is required")
+	}
+
+	if settings.Embeddings.URL == "" || settings.Embeddings.Model == "" {
+		return nil, fmt.Errorf("vector store embeddings url and model are required")
+	}
+
+	embedder := newEmbedder(settings)
+
+	switch settings.Provider {
+	case types.VectorStoreProviderQdrant:
+		return NewQdrant(settings.URL, settings.APIKey, settings.Collection, embedder), nil
+	case types.VectorStoreProviderWeaviate:
+		return NewWeaviate(settings.URL, settings.APIKey, settings.Collection, embedder)
+	case types.VectorStoreProviderPGVector:
+		return NewPGVector(settings.URL, settings.Collection, embedder)
+	default:
+		return nil, fmt.Errorf("unknown vector store provider '%s', expected pgvector, qdrant or weaviate", settings.Provider)
+	}
+}
+
+// VectorStores creates the clients for vector stores configured on knowledge,
+// resolving ${SECRET_NAME} references against the secrets of the knowledge
+// owner. There is one client per knowledge, it is replaced when the resolved
+// settings change, e.g. after a secret is rotated. Replaced clients are closed
+// once their in-flight calls return.
+type VectorStores struct {
+	store     store.Store
+	newClient func(settings *types.VectorStoreSettings) (RAG, error)
+
+	mu      sync.Mutex
+	clients map[string]*vectorStoreClient
+}
+
+// vectorStoreClient is what Get returns, it counts the calls in flight on the
+// underlying client so that it is only closed after they return. Calls made
+// after it was replaced go to the replacement.
+type vectorStoreClient struct {
+	stores      *VectorStores
+	knowledgeID string
+	// hash of the resolved settings, so secrets aren't kept around as map keys
+	settingsHash [sha256.Size]byte
+	client       RAG
+
+	mu       sync.Mutex
+	inFlight int
+	retired  bool
+}
+
+func NewVectorStores(store store.Store) *VectorStores {
+	return &VectorStores{
+		store:     store,
+		newClient: NewVectorStore,
+		clients:   make(map[string]*vectorStoreClient),
+	}
+}
+
+// Get returns the client for the vector store configured on the knowledge
+func (v *VectorStores) Get(ctx context.Context, k *types.Knowledge) (RAG, error) {
+	settings, err := v.resolveSettings(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+
+	bts, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	settingsHash := sha256.Sum256(bts)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if cached, ok := v.clients[k.ID]; ok {
+		if cached.settingsHash == settingsHash {
+			return cached, nil
+		}
+		v.remove(k.ID)
+	}
+
+	client, err := v.newClient(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vector store client for knowledge %s: %w", k.ID, err)
+	}
+	cached := &vectorStoreClient{
+		stores:       v,
+		knowledgeID:  k.ID,
+		settingsHash: settingsHash,
+		client:       client,
+	}
+	v.clients[k.ID] = cached
+
+	return cached, nil
+}
+
+// Remove closes the client of the knowledge, e.g. once it is deleted, after
+// its in-flight calls return
+func (v *VectorStores) Remove(knowledgeID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.remove(knowledgeID)
+}
+
+func (v *VectorStores) remove(knowledgeID string) {
+	cached, ok := v.clients[knowledgeID]
+	if !ok {
+		return
+	}
+	delete(v.clients, knowledgeID)
+
+	cached.retire()
+}
+
+func (v *VectorStores) current(knowledgeID string) *vectorStoreClient {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.clients[knowledgeID]
+}
+
+func (c *vectorStoreClient) Index(ctx context.Context, req ...*types.SessionRAGIndexChunk) error {
+	active, err := c.acquire()
+	if err != nil {
+		return err
+	}
+	defer active.release()
+
+	return active.client.Index(ctx, req...)
+}
+
+func (c *vectorStoreClient) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+	active, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer active.release()
+
+	return active.client.Query(ctx, q)
+}
+
+func (c *vectorStoreClient) Delete(ctx context.Context, req *types.DeleteIndexRequest) error {
+	active, err := c.acquire()
+	if err != nil {
+		return err
+	}
+	defer active.release()
+
+	return active.client.Delete(ctx, req)
+}
+
+// acquire returns the client to make a call on, following replacements, with
+// its in-flight count incremented. Callers must release it.
+func (c *vectorStoreClient) acquire() (*vectorStoreClient, error) {
+	active := c
+	for {
+		active.mu.Lock()
+		if !active.retired {
+			active.inFlight++
+			active.mu.Unlock()
+			return active, nil
+		}
+		active.mu.Unlock()
+
+		active = c.stores.current(c.knowledgeID)
+		if active == nil {
+			return nil, fmt.Errorf("vector store client for knowledge %s was removed", c.knowledgeID)
+		}
+	}
+}
+
+func (c *vectorStoreClient) release() {
+	c.mu.Lock()
+	c.inFlight--
+	idle := c.retired && c.inFlight == 0
+	c.mu.Unlock()
+
+	if idle {
+		c.close()
+	}
+}
+
+// retire stops new calls on the client and closes it once it is idle
+func (c *vectorStoreClient) retire() {
+	c.mu.Lock()
+	c.retired = true
+	idle := c.inFlight == 0
+	c.mu.Unlock()
+
+	if idle {
+		c.close()
+	}
+}
+
+func (c *vectorStoreClient) close() {
+	// Clients holding connections (pgvector) need closing, HTTP based ones don't
+	if closer, ok := c.client.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Warn().Err(err).Str("knowledge_id", c.knowledgeID).Msg("failed to close vector store client")
+		}
+	}
+}
+
+func (v *VectorStores) resolveSettings(ctx context.Context, k *types.Knowledge) (*types.VectorStoreSettings, error) {
+	settings := k.RAGSettings.VectorStore
+
+	fields := []*string{
+		&settings.URL,
+		&settings.APIKey,
+		&settings.Collection,
+		&settings.Embeddings.URL,
+		&settings.Embeddings.APIKey,
+		&settings.Embeddings.Model,
+	}
+
+	hasReferences := false
+	for _, field := range fields {
+		if strings.Contains(*field, "${") {
+			hasReferences = true
+		}
+	}
+	if !hasReferences {
+		return &settings, nil
+	}
+
+	secrets, err := v.store.ListSecrets(ctx, &store.ListSecretsQuery{
+		Owner:     k.Owner,
+		OwnerType: k.OwnerType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	envs := make(map[string]string)
+	for _, secret := range secrets {
+		// Secrets scoped to another app are not visible to this knowledge
+		if secret.AppID != "" && secret.AppID != k.AppID {
+			continue
+		}
+		envs[secret.Name] = string(secret.Value)
+	}
+
+	var missing []string
+	for _, field := range fields {
+		*field, err = envsubst.Eval(*field, func(name string) string {
+			value, ok := envs[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve vector store secrets: %w", err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("vector store settings reference unknown secrets: %s", strings.Join(missing, ", "))
+	}
+
+	return &settings, nil
+}
+
+// embedder computes embeddings through an OpenAI compatible API
+type embedder struct {
+	client     *openai.Client
+	model      string
+	dimensions int
+}
+
+func newEmbedder(settings *types.VectorStoreSettings) *embedder {
+	config := openai.DefaultConfig(settings.Embeddings.APIKey)
+	config.BaseURL = settings.Embeddings.URL
+
+	return &embedder{
+		client:     openai.NewClientWithConfig(config),
+		model:      settings.Embeddings.Model,
+		dimensions: settings.Embeddings.Dimensions,
+	}
+}
+
+func (e *embedder) embed(ctx context.Context, texts ...string) ([][]float32, error) {
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input:      texts,
+		Model:      openai.EmbeddingModel(e.model),
+		Dimensions: e.dimensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings: %w", err)
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
+
+func chunkContents(chunks []*types.SessionRAGIndexChunk) []string {
+	contents := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		contents = append(contents, chunk.Content)
+	}
+	return contents
+}
+
+func maxResults(q *types.SessionRAGQuery) int {
+	if q.MaxResults > 0 {
+		return q.MaxResults
+	}
+	return DefaultMaxResults
+}
+
+// httpStatusError is returned by doJSON for non 2xx responses
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("error response from server: %d (%s)", e.StatusCode, e.Body)
+}
+
+func isHTTPNotFound(err error) bool {
+	var statusErr *httpStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// doJSON sends the body as JSON and decodes the response into v if set
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body, v interface{}) error {
+	var reqBody io.Reader = http.NoBody
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(bts)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return &httpStatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if v != nil {
+		if err := json.Unmarshal(respBody, v); err != nil {
+			return fmt.Errorf("error parsing JSON (%s): %w", string(respBody), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,469 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestEmbedder(t *testing.T) *embedder {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/embeddings", r.URL.Path)
+		require.Equal(t, "Bearer embed-key", r.Header.Get("Authorization"))
+
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "test-embed", req.Model)
+
+		data := make([]map[string]interface{}, 0, len(req.Input))
+		for i, input := range req.Input {
+			data = append(data, map[string]interface{}{
+				"object":    "embedding",
+				"index":     i,
+				"embedding": []float32{float32(len(input)), 1, 0},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "data": data})
+	}))
+	t.Cleanup(srv.Close)
+
+	settings := &types.VectorStoreSettings{}
+	settings.Embeddings.URL = srv.URL
+	settings.Embeddings.APIKey = "embed-key"
+	settings.Embeddings.Model = "test-embed"
+
+	return newEmbedder(settings)
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// newRecordingServer records requests and responds with the handler result,
+// returning nil from the handler responds with 404
+func newRecordingServer(t *testing.T, handler func(r recordedRequest) interface{}) (*httptest.Server, func() []recordedRequest) {
+	var (
+		mu       sync.Mutex
+		requests []recordedRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{Method: r.Method, Path: r.URL.Path}
+		if r.ContentLength > 0 {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req.Body))
+		}
+
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		resp := handler(req)
+		if resp == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestNewVectorStore_Validation(t *testing.T) {
+	settings := func(provider types.VectorStoreProvider, url, collection string) *types.VectorStoreSettings {
+		s := &types.VectorStoreSettings{Provider: provider, URL: url, Collection: collection}
+		s.Embeddings.URL = "http://localhost"
+		s.Embeddings.Model = "m"
+		return s
+	}
+
+	_, err := NewVectorStore(settings(types.VectorStoreProviderQdrant, "", ""))
+	require.Error(t, err)
+
+	noEmbeddings := settings(types.VectorStoreProviderQdrant, "http://localhost", "")
+	noEmbeddings.Embeddings.Model = ""
+	_, err = NewVectorStore(noEmbeddings)
+	require.Error(t, err)
+
+	_, err = NewVectorStore(settings("milvus", "http://localhost", ""))
+	require.Error(t, err)
+
+	_, err = NewVectorStore(settings(types.VectorStoreProviderWeaviate, "http://localhost", "docs"))
+	require.Error(t, err)
+
+	_, err = NewVectorStore(settings(types.VectorStoreProviderPGVector, "postgres://localhost/db", "docs; drop table"))
+	require.Error(t, err)
+
+	client, err := NewVectorStore(settings(types.VectorStoreProviderWeaviate, "http://localhost", ""))
+	require.NoError(t, err)
+	require.Equal(t, defaultWeaviateClass, client.(*Weaviate).class)
+}
+
+func TestQdrant(t *testing.T) {
+	collectionCreated := false
+	srv, requests := newRecordingServer(t, func(r recordedRequest) interface{} {
+		switch {
+		case r.Method == http.MethodGet && r.Path == "/collections/docs":
+			if !collectionCreated {
+				return nil
+			}
+		case r.Method == http.MethodPut && r.Path == "/collections/docs":
+			collectionCreated = true
+		case r.Path == "/collections/docs/points/search":
+			return map[string]interface{}{
+				"result": []map[string]interface{}{
+					{
+						"id":    "point-1",
+						"score": 0.75,
+						"payload": map[string]interface{}{
+							"document_id": "doc-1",
+							"source":      "https://example.com",
+							"content":     "hello",
+						},
+					},
+				},
+			}
+		}
+		return map[string]interface{}{"result": true}
+	})
+
+	q := NewQdrant(srv.URL, "qdrant-key", "docs", newTestEmbedder(t))
+	ctx := context.Background()
+
+	err := q.Index(ctx, &types.SessionRAGIndexChunk{DataEntityID: "de-1", Content: "hello"}, &types.SessionRAGIndexChunk{DataEntityID: "de-1", Content: "world!"})
+	require.NoError(t, err)
+
+	results, err := q.Query(ctx, &types.SessionRAGQuery{Prompt: "hi", DataEntityID: "de-1", MaxResults: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "point-1", results[0].ID)
+	require.Equal(t, "doc-1", results[0].DocumentID)
+	require.Equal(t, "hello", results[0].Content)
+	require.InDelta(t, 0.25, results[0].Distance, 0.0001)
+
+	require.NoError(t, q.Delete(ctx, &types.DeleteIndexRequest{DataEntityID: "de-1"}))
+
+	reqs := requests()
+	require.Len(t, reqs, 6)
+
+	// Collection is created with the embedding dimensions and an index on the data entity
+	require.Equal(t, http.MethodPut, reqs[1].Method)
+	require.Equal(t, float64(3), reqs[1].Body["vectors"].(map[string]interface{})["size"])
+	require.Equal(t, "/collections/docs/index", reqs[2].Path)
+
+	points := reqs[3].Body["points"].([]interface{})
+	require.Len(t, points, 2)
+	require.Equal(t, "de-1", points[0].(map[string]interface{})["payload"].(map[string]interface{})["data_entity_id"])
+
+	require.Equal(t, float64(2), reqs[4].Body["limit"])
+	require.Contains(t, mustJSON(t, reqs[4].Body["filter"]), `"value":"de-1"`)
+	require.Equal(t, "/collections/docs/points/delete", reqs[5].Path)
+}
+
+func TestQdrant_QueryBeforeIndex(t *testing.T) {
+	srv, _ := newRecordingServer(t, func(_ recordedRequest) interface{} {
+		return nil
+	})
+
+	q := NewQdrant(srv.URL, "", "docs", newTestEmbedder(t))
+
+	results, err := q.Query(context.Background(), &types.SessionRAGQuery{Prompt: "hi", DataEntityID: "de-1"})
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	require.NoError(t, q.Delete(context.Background(), &types.DeleteIndexRequest{DataEntityID: "de-1"}))
+}
+
+func TestWeaviate(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(r recordedRequest) interface{} {
+		switch {
+		case r.Method == http.MethodGet && r.Path == "/v1/schema/Docs":
+			return nil
+		case r.Path == "/v1/batch/objects" && r.Method == http.MethodPost:
+			return []map[string]interface{}{{"result": map[string]interface{}{}}}
+		case r.Path == "/v1/graphql":
+			return map[string]interface{}{
+				"data": map[string]interface{}{
+					"Get": map[string]interface{}{
+						"Docs": []map[string]interface{}{
+							{
+								"document_id": "doc-1",
+								"content":     "hello",
+								"_additional": map[string]interface{}{"id": "obj-1", "distance": 0.2},
+							},
+						},
+					},
+				},
+			}
+		}
+		return map[string]interface{}{}
+	})
+
+	w, err := NewWeaviate(srv.URL, "weaviate-key", "Docs", newTestEmbedder(t))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, w.Index(ctx, &types.SessionRAGIndexChunk{DataEntityID: "de-1", Content: "hello"}))
+
+	results, err := w.Query(ctx, &types.SessionRAGQuery{Prompt: "hi", DataEntityID: "de-1"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "obj-1", results[0].ID)
+	require.Equal(t, "doc-1", results[0].DocumentID)
+	require.InDelta(t, 0.2, results[0].Distance, 0.0001)
+
+	require.NoError(t, w.Delete(ctx, &types.DeleteIndexRequest{DataEntityID: "de-1"}))
+
+	reqs := requests()
+	require.Len(t, reqs, 5)
+	require.Equal(t, "/v1/schema", reqs[1].Path)
+	require.Equal(t, "none", reqs[1].Body["vectorizer"])
+
+	query := reqs[3].Body["query"].(string)
+	require.Contains(t, query, "Docs(")
+	require.Contains(t, query, `valueText: "de-1"`)
+
+	require.Equal(t, http.MethodDelete, reqs[4].Method)
+	require.Contains(t, mustJSON(t, reqs[4].Body), `"valueText":"de-1"`)
+}
+
+func TestWeaviate_IndexObjectError(t *testing.T) {
+	srv, _ := newRecordingServer(t, func(r recordedRequest) interface{} {
+		if r.Path == "/v1/batch/objects" {
+			return []map[string]interface{}{{
+				"result": map[string]interface{}{
+					"errors": map[string]interface{}{
+						"error": []map[string]interface{}{{"message": "vector lengths don't match"}},
+					},
+				},
+			}}
+		}
+		return map[string]interface{}{}
+	})
+
+	w, err := NewWeaviate(srv.URL, "", "Docs", newTestEmbedder(t))
+	require.NoError(t, err)
+
+	err = w.Index(context.Background(), &types.SessionRAGIndexChunk{DataEntityID: "de-1", Content: "hello"})
+	require.ErrorContains(t, err, "vector lengths don't match")
+}
+
+func TestVectorStores_ResolveSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	storeMock.EXPECT().ListSecrets(gomock.Any(), &store.ListSecretsQuery{
+		Owner:     "user-1",
+		OwnerType: types.OwnerTypeUser,
+	}).Return([]*types.Secret{
+		{Name: "QDRANT_KEY", Value: []byte("qdrant-secret")},
+		{Name: "EMBED_KEY", Value: []byte("embed-secret"), AppID: "app-1"},
+		{Name: "OTHER_KEY", Value: []byte("other-secret"), AppID: "app-2"},
+	}, nil).Times(2)
+
+	var created []*types.VectorStoreSettings
+	vs := NewVectorStores(storeMock)
+	vs.newClient = func(settings *types.VectorStoreSettings) (RAG, error) {
+		created = append(created, settings)
+		return NewMockRAG(ctrl), nil
+	}
+
+	k := &types.Knowledge{
+		ID:        "knowledge-1",
+		Owner:     "user-1",
+		OwnerType: types.OwnerTypeUser,
+		AppID:     "app-1",
+	}
+	k.RAGSettings.VectorStore = types.VectorStoreSettings{
+		Provider: types.VectorStoreProviderQdrant,
+		URL:      "https://qdrant.example.com",
+		APIKey:   "${QDRANT_KEY}",
+	}
+	k.RAGSettings.VectorStore.Embeddings.URL = "https://embeddings.example.com/v1"
+	k.RAGSettings.VectorStore.Embeddings.APIKey = "${EMBED_KEY}"
+	k.RAGSettings.VectorStore.Embeddings.Model = "text-embedding-3-small"
+
+	first, err := vs.Get(context.Background(), k)
+	require.NoError(t, err)
+
+	second, err := vs.Get(context.Background(), k)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	require.Len(t, created, 1)
+	require.Equal(t, "qdrant-secret", created[0].APIKey)
+	require.Equal(t, "embed-secret", created[0].Embeddings.APIKey)
+	require.Equal(t, "${QDRANT_KEY}", k.RAGSettings.VectorStore.APIKey)
+
+	// Secrets scoped to other apps are not visible
+	k.RAGSettings.VectorStore.APIKey = "${OTHER_KEY}"
+	storeMock.EXPECT().ListSecrets(gomock.Any(), gomock.Any()).Return([]*types.Secret{
+		{Name: "OTHER_KEY", Value: []byte("other-secret"), AppID: "app-2"},
+	}, nil)
+
+	_, err = vs.Get(context.Background(), k)
+	require.ErrorContains(t, err, "OTHER_KEY")
+}
+
+func TestVectorStores_NoSecretReferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	vs := NewVectorStores(storeMock)
+	vs.newClient = func(_ *types.VectorStoreSettings) (RAG, error) {
+		return NewMockRAG(ctrl), nil
+	}
+
+	_, err := vs.Get(context.Background(), &types.Knowledge{
+		RAGSettings: types.RAGSettings{
+			VectorStore: types.VectorStoreSettings{
+				Provider: types.VectorStoreProviderPGVector,
+				URL:      "postgres://localhost/vectors",
+			},
+		},
+	})
+	require.NoError(t, err)
+}
+
+// closableRAG records whether the cache closed the client
+type closableRAG struct {
+	*MockRAG
+	closed bool
+}
+
+func (c *closableRAG) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestVectorStores_ReplacesClientWhenSettingsChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	secret := "key-1"
+	storeMock.EXPECT().ListSecrets(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *store.ListSecretsQuery) ([]*types.Secret, error) {
+			return []*types.Secret{{Name: "PG_PASSWORD", Value: []byte(secret)}}, nil
+		}).AnyTimes()
+
+	var created []*closableRAG
+	vs := NewVectorStores(storeMock)
+	vs.newClient = func(_ *types.VectorStoreSettings) (RAG, error) {
+		client := &closableRAG{MockRAG: NewMockRAG(ctrl)}
+		created = append(created, client)
+		return client, nil
+	}
+
+	newKnowledge := func(id string) *types.Knowledge {
+		k := &types.Knowledge{ID: id, Owner: "user-1", OwnerType: types.OwnerTypeUser}
+		k.RAGSettings.VectorStore = types.VectorStoreSettings{
+			Provider: types.VectorStoreProviderPGVector,
+			URL:      "postgres://helix:${PG_PASSWORD}@db/vectors",
+		}
+		return k
+	}
+
+	first, err := vs.Get(context.Background(), newKnowledge("knowledge-1"))
+	require.NoError(t, err)
+
+	// Every knowledge has its own client
+	_, err = vs.Get(context.Background(), newKnowledge("knowledge-2"))
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	// Rotating the secret replaces the client and closes the old one
+	secret = "key-2"
+	second, err := vs.Get(context.Background(), newKnowledge("knowledge-1"))
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+	require.True(t, created[0].closed)
+	require.False(t, created[1].closed)
+	require.Len(t, vs.clients, 2)
+
+	vs.Remove("knowledge-1")
+	require.True(t, created[2].closed)
+	require.Len(t, vs.clients, 1)
+}
+
+func TestVectorStores_ClosesReplacedClientAfterInFlightCalls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	var created []*closableRAG
+	vs := NewVectorStores(storeMock)
+	vs.newClient = func(_ *types.VectorStoreSettings) (RAG, error) {
+		client := &closableRAG{MockRAG: NewMockRAG(ctrl)}
+		created = append(created, client)
+		return client, nil
+	}
+
+	k := &types.Knowledge{ID: "knowledge-1"}
+	k.RAGSettings.VectorStore = types.VectorStoreSettings{
+		Provider: types.VectorStoreProviderPGVector,
+		URL:      "postgres://db/vectors",
+	}
+
+	first, err := vs.Get(context.Background(), k)
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	created[0].EXPECT().Query(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+			close(started)
+			<-finish
+			return nil, nil
+		})
+
+	done := make(chan error)
+	go func() {
+		_, err := first.Query(context.Background(), &types.SessionRAGQuery{})
+		done <- err
+	}()
+	<-started
+
+	// The settings change while the query is running
+	k.RAGSettings.VectorStore.URL = "postgres://db2/vectors"
+	_, err = vs.Get(context.Background(), k)
+	require.NoError(t, err)
+	require.False(t, created[0].closed, "closed while a query was in flight")
+
+	// Calls on the old client go to its replacement
+	created[1].EXPECT().Index(gomock.Any(), gomock.Any()).Return(nil)
+	err = first.Index(context.Background(), &types.SessionRAGIndexChunk{DataEntityID: "de-1"})
+	require.NoError(t, err)
+
+	close(finish)
+	require.NoError(t, <-done)
+	require.True(t, created[0].closed)
+
+	// Once the knowledge is removed there is nothing to forward to
+	vs.Remove("knowledge-1")
+	require.True(t, created[1].closed)
+	err = first.Index(context.Background(), &types.SessionRAGIndexChunk{DataEntityID: "de-1"})
+	require.ErrorContains(t, err, "was removed")
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	bts, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(bts)
+}
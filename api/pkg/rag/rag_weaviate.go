@@ -0,0 +1,271 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const defaultWeaviateClass = "HelixDocuments"
+
+var weaviateClassPattern = regexp.MustCompile(`^[A-Z][_0-9A-Za-z]*$`)
+
+// Static check
+var _ RAG = &Weaviate{}
+
+// Weaviate stores chunks as objects of a Weaviate class with our own vectors
+// (vectorizer "none"), the class is created on first index
+type Weaviate struct {
+	url        string
+	apiKey     string
+	class      string
+	embedder   *embedder
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	classReady bool
+}
+
+func NewWeaviate(baseURL, apiKey, class string, embedder *embedder) (*Weaviate, error) {
+	if class == "" {
+		class = defaultWeaviateClass
+	}
+
+	if !weaviateClassPattern.MatchString(class) {
+		return nil, fmt.Errorf("invalid weaviate class name '%s', it must start with an uppercase letter", class)
+	}
+
+	return &Weaviate{
+		url:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		class:      class,
+		embedder:   embedder,
+		httpClient: http.DefaultClient,
+	}, nil
+}
+
+type weaviateObject struct {
+	Class      string                      `json:"class"`
+	Properties *types.SessionRAGIndexChunk `json:"properties"`
+	Vector     []float32                   `json:"vector"`
+}
+
+func (w *Weaviate) dataEntityWhere(dataEntityID string) map[string]interface{} {
+	return map[string]interface{}{
+		"path":      []string{"data_entity_id"},
+		"operator":  "Equal",
+		"valueText": dataEntityID,
+	}
+}
+
+func (w *Weaviate) Index(ctx context.Context, indexReqs ...*types.SessionRAGIndexChunk) error {
+	if len(indexReqs) == 0 {
+		return fmt.Errorf("no index requests provided")
+	}
+
+	embeddings, err := w.embedder.embed(ctx, chunkContents(indexReqs)...)
+	if err != nil {
+		return err
+	}
+
+	if err := w.ensureClass(ctx); err != nil {
+		return err
+	}
+
+	objects := make([]weaviateObject, 0, len(indexReqs))
+	for i, indexReq := range indexReqs {
+		objects = append(objects, weaviateObject{
+			Class:      w.class,
+			Properties: indexReq,
+			Vector:     embeddings[i],
+		})
+	}
+
+	var results []struct {
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+
+	err = w.do(ctx, http.MethodPost, "/v1/batch/objects", map[string]interface{}{"objects": objects}, &results)
+	if err != nil {
+		return err
+	}
+
+	// Batch requests succeed as a whole, errors are reported per object
+	for _, result := range results {
+		if result.Result.Errors != nil && len(result.Result.Errors.Error) > 0 {
+			return fmt.Errorf("failed to index object: %s", result.Result.Errors.Error[0].Message)
+		}
+	}
+
+	return nil
+}
+
+func (w *Weaviate) Query(ctx context.Context, q *types.SessionRAGQuery) ([]*types.SessionRAGResult, error) {
+	if q.Prompt == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	embeddings, err := w.embedder.embed(ctx, q.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	vector, err := json.Marshal(embeddings[0])
+	if err != nil {
+		return nil, err
+	}
+	dataEntityID, err := json.Marshal(q.DataEntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`{
+  Get {
+    %s(
+      nearVector: {vector: %s}
+      where: {path: ["data_entity_id"], operator: Equal, valueText: %s}
+      limit: %d
+    ) {
+      data_entity_id document_id document_group_id filename source content_offset content
+      _additional { id distance }
+    }
+  }
+}`, w.class, vector, dataEntityID, maxResults(q))
+
+	var resp struct {
+		Data struct {
+			Get map[string][]struct {
+				types.SessionRAGIndexChunk
+				Additional struct {
+					ID       string  `json:"id"`
+					Distance float64 `json:"distance"`
+				} `json:"_additional"`
+			} `json:"Get"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	err = w.do(ctx, http.MethodPost, "/v1/graphql", map[string]string{"query": query}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Errors) > 0 {
+		// Nothing has been indexed yet
+		if !w.isClassReady() && strings.Contains(resp.Errors[0].Message, "Cannot query field") {
+			return []*types.SessionRAGResult{}, nil
+		}
+		return nil, fmt.Errorf("weaviate query failed: %s", resp.Errors[0].Message)
+	}
+
+	objects := resp.Data.Get[w.class]
+	results := make([]*types.SessionRAGResult, 0, len(objects))
+	for _, obj := range objects {
+		results = append(results, &types.SessionRAGResult{
+			ID:              obj.Additional.ID,
+			DocumentID:      obj.DocumentID,
+			DocumentGroupID: obj.DocumentGroupID,
+			Filename:        obj.Filename,
+			Source:          obj.Source,
+			ContentOffset:   obj.ContentOffset,
+			Content:         obj.Content,
+			Distance:        obj.Additional.Distance,
+		})
+	}
+
+	return results, nil
+}
+
+func (w *Weaviate) Delete(ctx context.Context, r *types.DeleteIndexRequest) error {
+	if r.DataEntityID == "" {
+		return fmt.Errorf("data entity ID cannot be empty")
+	}
+
+	err := w.do(ctx, http.MethodDelete, "/v1/batch/objects", map[string]interface{}{
+		"match": map[string]interface{}{
+			"class": w.class,
+			"where": w.dataEntityWhere(r.DataEntityID),
+		},
+	}, nil)
+	if isHTTPNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (w *Weaviate) isClassReady() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.classReady
+}
+
+func (w *Weaviate) ensureClass(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.classReady {
+		return nil
+	}
+
+	err := w.do(ctx, http.MethodGet, "/v1/schema/"+url.PathEscape(w.class), nil, nil)
+	if isHTTPNotFound(err) {
+		property := func(name, dataType string) map[string]interface{} {
+			p := map[string]interface{}{
+				"name":     name,
+				"dataType": []string{dataType},
+			}
+			if dataType == "text" && name != "content" {
+				// Match identifiers exactly instead of by word
+				p["tokenization"] = "field"
+			}
+			return p
+		}
+
+		err = w.do(ctx, http.MethodPost, "/v1/schema", map[string]interface{}{
+			"class":      w.class,
+			"vectorizer": "none",
+			"properties": []map[string]interface{}{
+				property("data_entity_id", "text"),
+				property("document_id", "text"),
+				property("document_group_id", "text"),
+				property("filename", "text"),
+				property("source", "text"),
+				property("content_offset", "int"),
+				property("content", "text"),
+			},
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create weaviate class %s: %w", w.class, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	w.classReady = true
+	return nil
+}
+
+func (w *Weaviate) do(ctx context.Context, method, path string, body interface{}, v interface{}) error {
+	header := http.Header{}
+	if w.apiKey != "" {
+		header.Set("Authorization", "Bearer "+w.apiKey)
+	}
+
+	return doJSON(ctx, w.httpClient, method, w.url+path, header, body, v)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// listAgentMemories godoc
+// @Summary List agent memories
+// @Description List the notes saved for the app by the user or their agents. They are added to the system prompt of the user's future sessions with the app.
+// @Tags    apps
+// @Produce json
+// @Param   id path string true "App ID"
+// @Success 200 {array} types.AgentMemory
+// @Router /api/v1/apps/{id}/memories [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listAgentMemories(_ http.ResponseWriter, r *http.Request) ([]*types.AgentMemory, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	app, httpError := s.loadAppForMemories(r, user)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	memories, err := s.Controller.ListAgentMemories(r.Context(), user, app.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return memories, nil
+}
+
+// createAgentMemory godoc
+// @Summary Create an agent memory
+// @Description Save a note (a fact about the codebase, a previous decision, ...) that will be added to future sessions with the app.
+// @Tags    apps
+// @Produce json
+// @Param   id      path string                   true "App ID"
+// @Param   request body types.AgentMemoryRequest true "Memory content"
+// @Success 200 {object} types.AgentMemory
+// @Router /api/v1/apps/{id}/memories [post]
+// @Security BearerAuth
+func (s *HelixAPIServer) createAgentMemory(_ http.ResponseWriter, r *http.Request) (*types.AgentMemory, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	app, httpError := s.loadAppForMemories(r, user)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	var req types.AgentMemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	memory, err := s.Controller.CreateAgentMemory(r.Context(), user, app.ID, &req)
+	if err != nil {
+		return nil, agentMemoryError(err)
+	}
+
+	return memory, nil
+}
+
+// updateAgentMemory godoc
+// @Summary Update an agent memory
+// @Tags    apps
+// @Produce json
+// @Param   id        path string                   true "App ID"
+// @Param   memory_id path string                   true "Memory ID"
+// @Param   request   body types.AgentMemoryRequest true "Memory content"
+// @Success 200 {object} types.AgentMemory
+// @Router /api/v1/apps/{id}/memories/{memory_id} [put]
+// @Security BearerAuth
+func (s *HelixAPIServer) updateAgentMemory(_ http.ResponseWriter, r *http.Request) (*types.AgentMemory, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	app, httpError := s.loadAppForMemories(r, user)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	var req types.AgentMemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	memory, err := s.Controller.UpdateAgentMemory(r.Context(), user, app.ID, mux.Vars(r)["memory_id"], &req)
+	if err != nil {
+		return nil, agentMemoryError(err)
+	}
+
+	return memory, nil
+}
+
+// deleteAgentMemory godoc
+// @Summary Delete an agent memory
+// @Tags    apps
+// @Param   id        path string true "App ID"
+// @Param   memory_id path string true "Memory ID"
+// @Success 200
+// @Router /api/v1/apps/{id}/memories/{memory_id} [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) deleteAgentMemory(_ http.ResponseWriter, r *http.Request) (string, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	app, httpError := s.loadAppForMemories(r, user)
+	if httpError != nil {
+		return "", httpError
+	}
+
+	id := mux.Vars(r)["memory_id"]
+	if err := s.Controller.DeleteAgentMemory(r.Context(), user, app.ID, id); err != nil {
+		return "", agentMemoryError(err)
+	}
+
+	return id, nil
+}
+
+// loadAppForMemories loads the app if the user can run it, memories are private
+// to each user so anyone who can use the app can keep their own
+func (s *HelixAPIServer) loadAppForMemories(r *http.Request, user *types.User) (*types.App, *system.HTTPError) {
+	app, err := s.Store.GetApp(r.Context(), getID(r))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	if !app.Global && !app.Shared && app.Owner != user.ID {
+		return nil, system.NewHTTPError403("you do not have access to this app")
+	}
+
+	return app, nil
+}
+
+func agentMemoryError(err error) *system.HTTPError {
+	switch {
+	case errors.Is(err, controller.ErrInvalidAgentMemory):
+		return system.NewHTTPError400(err.Error())
+	case errors.Is(err, store.ErrNotFound):
+		return system.NewHTTPError404(store.ErrNotFound.Error())
+	default:
+		return system.NewHTTPError500(err.Error())
+	}
+}
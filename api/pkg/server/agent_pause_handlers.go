@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// getAgentPauseStatus godoc
+// @Summary Get agent pause status
+// @Description Check whether LLM calls and tool execution are paused for the user, either by themselves or globally by an admin.
+// @Tags    agents
+// @Success 200 {object} types.AgentPauseStatus
+// @Router /api/v1/agents/pause [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getAgentPauseStatus(_ http.ResponseWriter, r *http.Request) (*types.AgentPauseStatus, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	status, err := s.Controller.GetAgentPauseStatus(r.Context(), user.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return status, nil
+}
+
+// pauseAgents godoc
+// @Summary Pause all agents
+// @Description Immediately stop LLM calls and tool execution for all of the user's sessions. Admins can set "global" to pause every session on the instance.
+// @Tags    agents
+// @Success 200 {object} types.AgentPause
+// @Param request body types.AgentPauseRequest true "Request body with the pause scope and reason."
+// @Router /api/v1/agents/pause [post]
+// @Security BearerAuth
+func (s *HelixAPIServer) pauseAgents(_ http.ResponseWriter, r *http.Request) (*types.AgentPause, *system.HTTPError) {
+	user := getRequestUser(r)
+
+	var req types.AgentPauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	if req.Global && !user.Admin {
+		return nil, system.NewHTTPError403("only admins can pause agents globally")
+	}
+
+	pause, err := s.Controller.PauseAgents(r.Context(), user, &req)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return pause, nil
+}
+
+// resumeAgents godoc
+// @Summary Resume agents
+// @Description Lift a pause set for the user's sessions, or the global pause if "global=true" is set by an admin.
+// @Tags    agents
+// @Success 200 {object} types.AgentPauseStatus
+// @Param global query bool false "Lift the global pause (admin only)"
+// @Router /api/v1/agents/pause [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) resumeAgents(_ http.ResponseWriter, r *http.Request) (*types.AgentPauseStatus, *system.HTTPError) {
+	user := getRequestUser(r)
+	global := r.URL.Query().Get("global") == "true"
+
+	if global && !user.Admin {
+		return nil, system.NewHTTPError403("only admins can resume agents globally")
+	}
+
+	err := s.Controller.ResumeAgents(r.Context(), user, global)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	status, err := s.Controller.GetAgentPauseStatus(r.Context(), user.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return status, nil
+}
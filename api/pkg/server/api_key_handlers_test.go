@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/extract"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/janitor"
+	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newAPIKeyTestServer(t *testing.T) (*HelixAPIServer, *store.MockStore) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+
+	cfg := &config.ServerConfig{}
+	cfg.Inference.Provider = types.ProviderTogetherAI
+
+	providerManager := manager.NewMockProviderManager(ctrl)
+	providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(openai.NewMockClient(ctrl), nil).AnyTimes()
+
+	c, err := controller.NewController(context.Background(), controller.Options{
+		Config:          cfg,
+		Store:           storeMock,
+		Janitor:         janitor.NewJanitor(config.Janitor{}),
+		ProviderManager: providerManager,
+		Filestore:       filestore.NewMockFileStore(ctrl),
+		Extractor:       extract.NewMockExtractor(ctrl),
+	})
+	require.NoError(t, err)
+
+	return &HelixAPIServer{
+		Cfg:        cfg,
+		Controller: c,
+		Store:      storeMock,
+	}, storeMock
+}
+
+func TestCreateAPIKey_PersonalScopedKey(t *testing.T) {
+	server, storeMock := newAPIKeyTestServer(t)
+
+	storeMock.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, apiKey *types.APIKey) (*types.APIKey, error) {
+			require.Nil(t, apiKey.AppID, "personal keys must not reference an app")
+			require.Equal(t, "user_id", apiKey.Owner)
+			require.Equal(t, types.StringArray{string(types.APIKeyScopeSessionsRead)}, apiKey.Scopes)
+			return apiKey, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api_keys", strings.NewReader(`{"name":"ci","type":"api","scopes":["sessions:read"]}`))
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_id"}))
+
+	key, err := server.createAPIKey(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+}
+
+func TestCreateAPIKey_AppKey(t *testing.T) {
+	server, storeMock := newAPIKeyTestServer(t)
+
+	storeMock.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, apiKey *types.APIKey) (*types.APIKey, error) {
+			require.NotNil(t, apiKey.AppID)
+			require.True(t, apiKey.AppID.Valid)
+			require.Equal(t, "app_123", apiKey.AppID.String)
+			return apiKey, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api_keys", strings.NewReader(`{"name":"app","type":"app","app_id":"app_123"}`))
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_id"}))
+
+	_, err := server.createAPIKey(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+const (
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	maxAnalyticsRange     = 366 * 24 * time.Hour
+)
+
+// getAppAnalytics godoc
+// @Summary Get app session analytics
+// @Description Aggregate the app's sessions (started, completed, failed, abandoned), average duration, average token usage and top failing tools, grouped by day, week or month.
+// @Tags    apps
+// @Produce json
+// @Param   id       path     string  true   "App ID"
+// @Param   from     query    string  false  "Start of the range (RFC3339), defaults to 30 days ago"
+// @Param   to       query    string  false  "End of the range (RFC3339), defaults to now"
+// @Param   interval query    string  false  "Bucket size: day, week or month (default day)"
+// @Success 200 {object} types.AppAnalytics
+// @Router /api/v1/apps/{id}/analytics [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getAppAnalytics(_ http.ResponseWriter, r *http.Request) (*types.AppAnalytics, *system.HTTPError) {
+	appID := getID(r)
+	user := getRequestUser(r)
+
+	app, err := s.Store.GetApp(r.Context(), appID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404(store.ErrNotFound.Error())
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	if app.Owner != user.ID && !isAdmin(user) {
+		return nil, system.NewHTTPError403("you do not have permission to view this app's analytics")
+	}
+
+	query, err := parseAppAnalyticsQuery(r, time.Now())
+	if err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+	query.AppID = app.ID
+
+	analytics, err := s.Controller.GetAppAnalytics(r.Context(), query)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return analytics, nil
+}
+
+func parseAppAnalyticsQuery(r *http.Request, now time.Time) (*types.AppAnalyticsQuery, error) {
+	query := &types.AppAnalyticsQuery{
+		From:     now.Add(-defaultAnalyticsRange),
+		To:       now,
+		Interval: types.AnalyticsIntervalDay,
+	}
+
+	var err error
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		query.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'from' time, expected RFC3339: %w", err)
+		}
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		query.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'to' time, expected RFC3339: %w", err)
+		}
+	}
+
+	if !query.From.Before(query.To) {
+		return nil, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	if query.To.Sub(query.From) > maxAnalyticsRange {
+		return nil, fmt.Errorf("time range must not exceed %d days", int(maxAnalyticsRange.Hours()/24))
+	}
+
+	if interval := r.URL.Query().Get("interval"); interval != "" {
+		switch types.AnalyticsInterval(interval) {
+		case types.AnalyticsIntervalDay, types.AnalyticsIntervalWeek, types.AnalyticsIntervalMonth:
+			query.Interval = types.AnalyticsInterval(interval)
+		default:
+			return nil, fmt.Errorf("invalid interval '%s', expected day, week or month", interval)
+		}
+	}
+
+	return query, nil
+}
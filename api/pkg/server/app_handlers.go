@@ -760,6 +760,10 @@ func (s *HelixAPIServer) appRunAPIAction(_ http.ResponseWriter, r *http.Request)
 
 	req.Tool = tool
 
+	if err := s.Controller.CheckAgentsPaused(r.Context(), user.ID); err != nil {
+		return nil, &system.HTTPError{StatusCode: http.StatusLocked, Message: err.Error()}
+	}
+
 	response, err := s.Controller.ToolsPlanner.RunAPIActionWithParameters(r.Context(), &req)
 	if err != nil {
 		return nil, system.NewHTTPError500(err.Error())
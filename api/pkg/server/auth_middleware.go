@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/helixml/helix/api/pkg/auth"
 	"github.com/helixml/helix/api/pkg/store"
 	"github.com/helixml/helix/api/pkg/types"
+	"github.com/rs/zerolog/log"
 )
 
 var (
@@ -26,18 +29,27 @@ var (
 	ErrNoAPIKeyFound           = errors.New("no API key found")
 	ErrNoUserIDFound           = errors.New("no user ID found")
 	ErrAppAPIKeyPathNotAllowed = errors.New("path not allowed for app API keys, use your personal account key from your /account page instead")
+	ErrAPIKeyExpired           = errors.New("API key has expired")
+	ErrAPIKeyIPNotAllowed      = errors.New("API key cannot be used from this IP address")
 )
 
+// last used time is only written once per interval to avoid a write per request
+const apiKeyLastUsedResolution = time.Minute
+
 type authMiddlewareConfig struct {
 	adminUserIDs []string
 	runnerToken  string
+	// the number of proxies in front of the api server that append to
+	// X-Forwarded-For, zero means the header is not trusted
+	trustedProxyHops int
 }
 
 type authMiddleware struct {
-	authenticator auth.Authenticator
-	store         store.Store
-	adminUserIDs  []string
-	runnerToken   string
+	authenticator    auth.Authenticator
+	store            store.Store
+	adminUserIDs     []string
+	runnerToken      string
+	trustedProxyHops int
 	// this means ALL users
 	// if '*' is included in the list
 	developmentMode bool
@@ -49,11 +61,12 @@ func newAuthMiddleware(
 	cfg authMiddlewareConfig,
 ) *authMiddleware {
 	return &authMiddleware{
-		authenticator:   authenticator,
-		store:           store,
-		adminUserIDs:    cfg.adminUserIDs,
-		runnerToken:     cfg.runnerToken,
-		developmentMode: isDevelopmentMode(cfg.adminUserIDs),
+		authenticator:    authenticator,
+		store:            store,
+		adminUserIDs:     cfg.adminUserIDs,
+		runnerToken:      cfg.runnerToken,
+		trustedProxyHops: cfg.trustedProxyHops,
+		developmentMode:  isDevelopmentMode(cfg.adminUserIDs),
 	}
 }
 
@@ -91,6 +104,9 @@ func (auth *authMiddleware) getUserFromToken(ctx context.Context, token string)
 		if apiKey == nil {
 			return nil, fmt.Errorf("error getting API key: %w", ErrNoAPIKeyFound)
 		}
+		if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+			return nil, ErrAPIKeyExpired
+		}
 
 		user, err := auth.authenticator.GetUserByID(ctx, apiKey.Owner)
 		if err != nil {
@@ -105,6 +121,7 @@ func (auth *authMiddleware) getUserFromToken(ctx context.Context, token string)
 		if apiKey.AppID != nil && apiKey.AppID.Valid {
 			user.AppID = apiKey.AppID.String
 		}
+		user.APIKey = apiKey
 
 		return user, nil
 	}
@@ -158,6 +175,13 @@ func (auth *authMiddleware) extractMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
+		if user.APIKey != nil {
+			if err := auth.authorizeAPIKey(r, user.APIKey); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+
 		r = r.WithContext(setRequestUser(r.Context(), *user))
 		next.ServeHTTP(w, r)
 	}
@@ -183,8 +207,113 @@ func (auth *authMiddleware) auth(f http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
+		if user.APIKey != nil {
+			if err := auth.authorizeAPIKey(r, user.APIKey); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+
 		r = r.WithContext(setRequestUser(r.Context(), *user))
 
 		f(w, r)
 	}
 }
+
+// authorizeAPIKey enforces the key's IP allowlist and scopes for the request and
+// records when and where the key was last used
+func (auth *authMiddleware) authorizeAPIKey(r *http.Request, apiKey *types.APIKey) error {
+	ip := getClientIP(r, auth.trustedProxyHops)
+
+	if len(apiKey.IPAllowlist) > 0 && !isIPAllowed(ip, apiKey.IPAllowlist) {
+		return ErrAPIKeyIPNotAllowed
+	}
+
+	scope := requiredAPIKeyScope(r)
+	if !apiKey.HasScope(scope) {
+		return fmt.Errorf("API key is missing the '%s' scope", scope)
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyLastUsedResolution || apiKey.LastUsedIP != ip {
+		// don't fail the request if we can't record usage
+		if err := auth.store.UpdateAPIKeyLastUsed(r.Context(), apiKey.Key, now, ip); err != nil {
+			log.Warn().Err(err).Msg("failed to update API key last used")
+		}
+	}
+
+	return nil
+}
+
+// requiredAPIKeyScope maps a request to the scope a scoped API key needs for it.
+// Anything not listed here, including API key management, needs the admin scope.
+func requiredAPIKeyScope(r *http.Request) types.APIKeyScope {
+	path := r.URL.Path
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	switch {
+	case path == "/v1/chat/completions", path == "/v1/models", path == APIPrefix+"/sessions/chat",
+		strings.HasPrefix(path, "/openai/deployments/"):
+		return types.APIKeyScopeInferenceWrite
+	case path == APIPrefix+"/ws/user":
+		return types.APIKeyScopeSessionsRead
+	case path == APIPrefix+"/sessions" || strings.HasPrefix(path, APIPrefix+"/sessions/"):
+		if read {
+			return types.APIKeyScopeSessionsRead
+		}
+		return types.APIKeyScopeSessionsWrite
+	case path == APIPrefix+"/apps" || strings.HasPrefix(path, APIPrefix+"/apps/"):
+		if read {
+			return types.APIKeyScopeAppsRead
+		}
+		return types.APIKeyScopeAppsWrite
+	}
+
+	return types.APIKeyScopeAdmin
+}
+
+// getClientIP returns the address of the client as seen by the outermost of
+// our trusted proxies. Each proxy appends the address it received the request
+// from to X-Forwarded-For, so only the last trustedProxyHops entries can be
+// trusted, anything before them is set by the client.
+func getClientIP(r *http.Request, trustedProxyHops int) string {
+	if trustedProxyHops > 0 {
+		var forwarded []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, entry := range strings.Split(header, ",") {
+				forwarded = append(forwarded, strings.TrimSpace(entry))
+			}
+		}
+		// fewer entries than proxies means the request didn't come through all of
+		// them, fall back to the connection address
+		if len(forwarded) >= trustedProxyHops {
+			return forwarded[len(forwarded)-trustedProxyHops]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isIPAllowed(ip string, allowlist []string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range allowlist {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestRequiredAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   types.APIKeyScope
+	}{
+		{http.MethodPost, "/v1/chat/completions", types.APIKeyScopeInferenceWrite},
+		{http.MethodPost, "/openai/deployments/llama3/chat/completions", types.APIKeyScopeInferenceWrite},
+		{http.MethodPost, "/api/v1/sessions/chat", types.APIKeyScopeInferenceWrite},
+		{http.MethodGet, "/api/v1/sessions", types.APIKeyScopeSessionsRead},
+		{http.MethodGet, "/api/v1/sessions/ses_1", types.APIKeyScopeSessionsRead},
+		{http.MethodDelete, "/api/v1/sessions/ses_1", types.APIKeyScopeSessionsWrite},
+		{http.MethodGet, "/api/v1/ws/user", types.APIKeyScopeSessionsRead},
+		{http.MethodGet, "/api/v1/apps/app_1", types.APIKeyScopeAppsRead},
+		{http.MethodPut, "/api/v1/apps/app_1", types.APIKeyScopeAppsWrite},
+		{http.MethodPost, "/api/v1/api_keys", types.APIKeyScopeAdmin},
+		{http.MethodGet, "/api/v1/secrets", types.APIKeyScopeAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			require.Equal(t, tt.want, requiredAPIKeyScope(r))
+		})
+	}
+}
+
+func TestIsIPAllowed(t *testing.T) {
+	allowlist := []string{"10.0.0.0/8", "192.168.1.5", "2001:db8::/32"}
+
+	require.True(t, isIPAllowed("10.1.2.3", allowlist))
+	require.True(t, isIPAllowed("192.168.1.5", allowlist))
+	require.True(t, isIPAllowed("2001:db8::1", allowlist))
+	require.False(t, isIPAllowed("192.168.1.6", allowlist))
+	require.False(t, isIPAllowed("not-an-ip", allowlist))
+}
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		forwarded []string
+		hops      int
+		want      string
+	}{
+		{
+			name:      "headers not trusted",
+			forwarded: []string{"1.2.3.4"},
+			want:      "172.16.0.1",
+		},
+		{
+			name:      "single proxy",
+			forwarded: []string{"1.2.3.4"},
+			hops:      1,
+			want:      "1.2.3.4",
+		},
+		{
+			name:      "client supplied entries are ignored",
+			forwarded: []string{"10.0.0.1, 1.2.3.4"},
+			hops:      1,
+			want:      "1.2.3.4",
+		},
+		{
+			name:      "two proxies",
+			forwarded: []string{"10.0.0.1, 1.2.3.4, 172.16.0.2"},
+			hops:      2,
+			want:      "1.2.3.4",
+		},
+		{
+			name:      "repeated headers",
+			forwarded: []string{"10.0.0.1", "1.2.3.4"},
+			hops:      1,
+			want:      "1.2.3.4",
+		},
+		{
+			name:      "fewer entries than proxies",
+			forwarded: []string{"1.2.3.4"},
+			hops:      2,
+			want:      "172.16.0.1",
+		},
+		{
+			name: "no header",
+			hops: 1,
+			want: "172.16.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "172.16.0.1:1234"
+			for _, forwarded := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+
+			require.Equal(t, tt.want, getClientIP(r, tt.hops))
+		})
+	}
+}
+
+func TestAuthorizeAPIKey(t *testing.T) {
+	recently := time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name        string
+		apiKey      *types.APIKey
+		path        string
+		forwarded   string
+		wantErr     bool
+		wantLastUse bool
+	}{
+		{
+			name:        "unscoped key",
+			apiKey:      &types.APIKey{Key: "hl-1"},
+			path:        "/api/v1/api_keys",
+			wantLastUse: true,
+		},
+		{
+			name:    "missing scope",
+			apiKey:  &types.APIKey{Key: "hl-1", Scopes: types.StringArray{"sessions:read"}},
+			path:    "/v1/chat/completions",
+			wantErr: true,
+		},
+		{
+			name:        "admin scope allows everything",
+			apiKey:      &types.APIKey{Key: "hl-1", Scopes: types.StringArray{"admin"}},
+			path:        "/api/v1/api_keys",
+			wantLastUse: true,
+		},
+		{
+			name:    "ip not allowed",
+			apiKey:  &types.APIKey{Key: "hl-1", IPAllowlist: types.StringArray{"10.0.0.0/8"}},
+			path:    "/v1/chat/completions",
+			wantErr: true,
+		},
+		{
+			name:      "spoofed forwarded ip not allowed",
+			apiKey:    &types.APIKey{Key: "hl-1", IPAllowlist: types.StringArray{"10.0.0.0/8"}},
+			path:      "/v1/chat/completions",
+			forwarded: "10.0.0.1, 192.0.2.1",
+			wantErr:   true,
+		},
+		{
+			name:        "forwarded ip allowed",
+			apiKey:      &types.APIKey{Key: "hl-1", IPAllowlist: types.StringArray{"192.0.2.0/24"}},
+			path:        "/v1/chat/completions",
+			forwarded:   "10.0.0.1, 192.0.2.1",
+			wantLastUse: true,
+		},
+		{
+			name:   "last used recently from the same ip is not rewritten",
+			apiKey: &types.APIKey{Key: "hl-1", LastUsedAt: &recently, LastUsedIP: "192.0.2.1"},
+			path:   "/v1/chat/completions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storeMock := store.NewMockStore(ctrl)
+			if tt.wantLastUse {
+				storeMock.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), "hl-1", gomock.Any(), "192.0.2.1").Return(nil)
+			}
+
+			auth := newAuthMiddleware(nil, storeMock, authMiddlewareConfig{trustedProxyHops: 1})
+
+			r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.forwarded != "" {
+				r.RemoteAddr = "172.16.0.1:1234"
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			err := auth.authorizeAPIKey(r, tt.apiKey)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -157,6 +158,9 @@ func (apiServer *HelixAPIServer) updateSession(_ http.ResponseWriter, req *http.
 		UserInteraction: userInteraction,
 		SessionMode:     session.Mode,
 	})
+	if errors.Is(err, controller.ErrAgentsPaused) {
+		return nil, &system.HTTPError{StatusCode: http.StatusLocked, Message: err.Error()}
+	}
 	if err != nil {
 		return nil, system.NewHTTPError500(fmt.Sprintf("failed to update session: %s", err))
 	}
@@ -671,6 +675,10 @@ func (apiServer *HelixAPIServer) deleteSession(_ http.ResponseWriter, req *http.
 		return nil, httpError
 	}
 
+	if err := apiServer.Controller.DeleteSessionScratch(req.Context(), session); err != nil {
+		log.Warn().Err(err).Str("session_id", session.ID).Msg("failed to delete session scratch objects")
+	}
+
 	return system.DefaultController(apiServer.Store.DeleteSession(req.Context(), session.ID))
 }
 
@@ -686,34 +694,22 @@ func (apiServer *HelixAPIServer) createAPIKey(_ http.ResponseWriter, req *http.R
 		newAPIKey.Name = name
 		newAPIKey.Type = types.APIkeytypeAPI
 	} else {
-		// For now we need to manually unmarshal the body because of the sql.NullString
-		body, err := io.ReadAll(req.Body)
+		var createReq types.CreateAPIKeyRequest
+		err := json.NewDecoder(req.Body).Decode(&createReq)
 		if err != nil {
 			return "", err
 		}
-		var objmap map[string]json.RawMessage
-		err = json.Unmarshal(body, &objmap)
-		if err != nil {
-			return "", err
+		newAPIKey.Name = createReq.Name
+		newAPIKey.Type = createReq.Type
+		// Personal keys have no app, an empty app_id would break the foreign key
+		if createReq.AppID != "" {
+			newAPIKey.AppID = &sql.NullString{String: createReq.AppID, Valid: true}
 		}
-		var nameStr string
-		err = json.Unmarshal(objmap["name"], &nameStr)
-		if err != nil {
-			return "", err
-		}
-		var typeStr string
-		err = json.Unmarshal(objmap["type"], &typeStr)
-		if err != nil {
-			return "", err
-		}
-		var apiKeyStr string
-		err = json.Unmarshal(objmap["app_id"], &apiKeyStr)
-		if err != nil {
-			return "", err
+		newAPIKey.ExpiresAt = createReq.ExpiresAt
+		newAPIKey.IPAllowlist = createReq.IPAllowlist
+		for _, scope := range createReq.Scopes {
+			newAPIKey.Scopes = append(newAPIKey.Scopes, string(scope))
 		}
-		newAPIKey.Name = nameStr
-		newAPIKey.Type = types.APIKeyType(typeStr)
-		newAPIKey.AppID = &sql.NullString{String: apiKeyStr, Valid: true}
 	}
 
 	createdKey, err := apiServer.Controller.CreateAPIKey(ctx, user, newAPIKey)
@@ -133,6 +133,7 @@ func (s *HelixAPIServer) deleteKnowledgeAndVersions(k *types.Knowledge) error {
 				Str("data_entity_id", k.GetDataEntityID()).
 				Msg("error deleting knowledge")
 		}
+		s.Controller.ReleaseRagClient(k.ID)
 	}
 
 	// Delete all versions from the store
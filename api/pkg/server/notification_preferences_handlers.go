@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// getNotificationPreferences godoc
+// @Summary Get notification preferences
+// @Description Get the notification events the user has turned off for email and web push.
+// @Tags    notifications
+// @Success 200 {object} types.NotificationPreferences
+// @Router /api/v1/notifications/preferences [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getNotificationPreferences(_ http.ResponseWriter, r *http.Request) (*types.NotificationPreferences, *system.HTTPError) {
+	ctx := r.Context()
+	user := getRequestUser(r)
+	if user == nil {
+		return nil, system.NewHTTPError401("user not found")
+	}
+
+	prefs, err := s.Store.GetNotificationPreferences(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			// nothing turned off yet
+			return &types.NotificationPreferences{
+				Owner:                 user.ID,
+				OwnerType:             types.OwnerTypeUser,
+				DisabledEmailEvents:   types.StringArray{},
+				DisabledWebPushEvents: types.StringArray{},
+			}, nil
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return prefs, nil
+}
+
+// updateNotificationPreferences godoc
+// @Summary Update notification preferences
+// @Description Set the notification events the user doesn't want to receive by email or web push.
+// @Tags    notifications
+// @Success 200 {object} types.NotificationPreferences
+// @Param request body types.NotificationPreferences true "Disabled events, e.g. session_complete or approval_required."
+// @Router /api/v1/notifications/preferences [put]
+// @Security BearerAuth
+func (s *HelixAPIServer) updateNotificationPreferences(_ http.ResponseWriter, r *http.Request) (*types.NotificationPreferences, *system.HTTPError) {
+	ctx := r.Context()
+	user := getRequestUser(r)
+	if user == nil {
+		return nil, system.NewHTTPError401("user not found")
+	}
+
+	var req types.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	for _, event := range append(append([]string{}, req.DisabledEmailEvents...), req.DisabledWebPushEvents...) {
+		if !isNotificationEvent(event) {
+			return nil, system.NewHTTPError400(fmt.Sprintf("unknown notification event '%s'", event))
+		}
+	}
+
+	prefs, err := s.Store.UpdateNotificationPreferences(ctx, &types.NotificationPreferences{
+		Owner:                 user.ID,
+		OwnerType:             types.OwnerTypeUser,
+		DisabledEmailEvents:   req.DisabledEmailEvents,
+		DisabledWebPushEvents: req.DisabledWebPushEvents,
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return prefs, nil
+}
+
+func isNotificationEvent(name string) bool {
+	for _, event := range notification.Events {
+		if event.String() == name {
+			return true
+		}
+	}
+	return false
+}
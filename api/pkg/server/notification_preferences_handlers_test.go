@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func TestGetNotificationPreferences_Defaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	server := &HelixAPIServer{Store: storeMock}
+
+	storeMock.EXPECT().GetNotificationPreferences(gomock.Any(), "user_id").Return(nil, store.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/preferences", nil)
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_id"}))
+
+	prefs, httpErr := server.getNotificationPreferences(httptest.NewRecorder(), req)
+	require.Nil(t, httpErr)
+	require.Equal(t, "user_id", prefs.Owner)
+	require.Empty(t, prefs.DisabledWebPushEvents)
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	server := &HelixAPIServer{Store: storeMock}
+
+	storeMock.EXPECT().UpdateNotificationPreferences(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, prefs *types.NotificationPreferences) (*types.NotificationPreferences, error) {
+			require.Equal(t, "user_id", prefs.Owner)
+			require.Equal(t, types.StringArray{"session_complete"}, prefs.DisabledWebPushEvents)
+			return prefs, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/notifications/preferences",
+		strings.NewReader(`{"owner":"someone_else","disabled_web_push_events":["session_complete"]}`))
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_id"}))
+
+	_, httpErr := server.updateNotificationPreferences(httptest.NewRecorder(), req)
+	require.Nil(t, httpErr)
+}
+
+func TestUpdateNotificationPreferences_UnknownEvent(t *testing.T) {
+	server := &HelixAPIServer{}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/notifications/preferences",
+		strings.NewReader(`{"disabled_email_events":["everything"]}`))
+	req = req.WithContext(setRequestUser(req.Context(), types.User{ID: "user_id"}))
+
+	_, httpErr := server.updateNotificationPreferences(httptest.NewRecorder(), req)
+	require.NotNil(t, httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+}
@@ -167,7 +167,7 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 		resp, _, err := s.Controller.ChatCompletion(ctx, user, chatCompletionRequest, options)
 		if err != nil {
 			log.Error().Err(err).Msg("error creating chat completion")
-			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 			return
 		}
 
@@ -196,7 +196,7 @@ func (s *HelixAPIServer) createChatCompletion(rw http.ResponseWriter, r *http.Re
 	// Streaming request, receive and write the stream in chunks
 	stream, _, err := s.Controller.ChatCompletionStream(ctx, user, chatCompletionRequest, options)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 		return
 	}
 	defer stream.Close()
@@ -247,3 +247,12 @@ func (s *HelixAPIServer) getAppLoraAssistant(ctx context.Context, appID string)
 
 	return assistant, nil
 }
+
+// chatCompletionErrorStatus maps errors from the controller to a status code,
+// paused agents are not a server error
+func chatCompletionErrorStatus(err error) int {
+	if errors.Is(err, controller.ErrAgentsPaused) {
+		return http.StatusLocked
+	}
+	return http.StatusInternalServerError
+}
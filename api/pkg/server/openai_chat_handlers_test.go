@@ -75,6 +75,8 @@ func (suite *OpenAIChatSuite) SetupTest() {
 	ctrl := gomock.NewController(suite.T())
 
 	suite.store = store.NewMockStore(ctrl)
+	suite.store.EXPECT().GetAgentPause(gomock.Any(), gomock.Any()).Return(nil, store.ErrNotFound).AnyTimes()
+	suite.store.EXPECT().ListAgentMemories(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	ps, err := pubsub.New(suite.T().TempDir())
 	suite.NoError(err)
 
@@ -1061,3 +1063,8 @@ func (suite *OpenAIChatSuite) TestChatCompletions_App_CustomQueryParams() {
 	err = json.Unmarshal(rec.Body.Bytes(), &resp)
 	suite.NoError(err)
 }
+
+func TestChatCompletionErrorStatus(t *testing.T) {
+	require.Equal(t, http.StatusLocked, chatCompletionErrorStatus(fmt.Errorf("%w for your account", controller.ErrAgentsPaused)))
+	require.Equal(t, http.StatusInternalServerError, chatCompletionErrorStatus(fmt.Errorf("upstream failed")))
+}
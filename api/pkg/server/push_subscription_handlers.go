@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// getWebPushConfig godoc
+// @Summary Get Web Push configuration
+// @Description Get the VAPID key browsers need to subscribe to push notifications.
+// @Tags    notifications
+// @Success 200 {object} types.WebPushConfig
+// @Router /api/v1/notifications/web_push [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) getWebPushConfig(_ http.ResponseWriter, _ *http.Request) (*types.WebPushConfig, *system.HTTPError) {
+	if s.Cfg.Notifications.WebPush.VAPIDPrivateKey == "" {
+		return &types.WebPushConfig{Enabled: false}, nil
+	}
+
+	publicKey, err := notification.VAPIDPublicKey(s.Cfg.Notifications.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return &types.WebPushConfig{
+		Enabled:        true,
+		VAPIDPublicKey: publicKey,
+	}, nil
+}
+
+// createPushSubscription godoc
+// @Summary Subscribe to push notifications
+// @Description Register a browser push subscription for the user. Subscribing an endpoint again replaces it.
+// @Tags    notifications
+// @Success 200 {object} types.PushSubscription
+// @Param request body types.PushSubscriptionRequest true "Browser PushSubscription serialized with toJSON()."
+// @Router /api/v1/notifications/web_push/subscriptions [post]
+// @Security BearerAuth
+func (s *HelixAPIServer) createPushSubscription(_ http.ResponseWriter, r *http.Request) (*types.PushSubscription, *system.HTTPError) {
+	ctx := r.Context()
+	user := getRequestUser(r)
+	if user == nil {
+		return nil, system.NewHTTPError401("user not found")
+	}
+
+	if s.Cfg.Notifications.WebPush.VAPIDPrivateKey == "" {
+		return nil, system.NewHTTPError400("web push notifications are not enabled")
+	}
+
+	var req types.PushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	if err := notification.ValidatePushEndpoint(req.Endpoint); err != nil {
+		return nil, system.NewHTTPError400(err.Error())
+	}
+
+	if req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		return nil, system.NewHTTPError400("keys.p256dh and keys.auth are required")
+	}
+
+	sub, err := s.Store.CreatePushSubscription(ctx, &types.PushSubscription{
+		Owner:     user.ID,
+		OwnerType: types.OwnerTypeUser,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return sub, nil
+}
+
+// listPushSubscriptions godoc
+// @Summary List push subscriptions
+// @Description List the browsers the user has subscribed to push notifications.
+// @Tags    notifications
+// @Success 200 {array} types.PushSubscription
+// @Router /api/v1/notifications/web_push/subscriptions [get]
+// @Security BearerAuth
+func (s *HelixAPIServer) listPushSubscriptions(_ http.ResponseWriter, r *http.Request) ([]*types.PushSubscription, *system.HTTPError) {
+	ctx := r.Context()
+	user := getRequestUser(r)
+	if user == nil {
+		return nil, system.NewHTTPError401("user not found")
+	}
+
+	subs, err := s.Store.ListPushSubscriptions(ctx, user.ID)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return subs, nil
+}
+
+// deletePushSubscription godoc
+// @Summary Unsubscribe from push notifications
+// @Description Remove a browser push subscription.
+// @Tags    notifications
+// @Success 200 {object} types.PushSubscription
+// @Param id path string true "Subscription ID"
+// @Router /api/v1/notifications/web_push/subscriptions/{id} [delete]
+// @Security BearerAuth
+func (s *HelixAPIServer) deletePushSubscription(_ http.ResponseWriter, r *http.Request) (*types.PushSubscription, *system.HTTPError) {
+	ctx := r.Context()
+	id := getID(r)
+
+	user := getRequestUser(r)
+	if user == nil {
+		return nil, system.NewHTTPError401("user not found")
+	}
+
+	existing, err := s.Store.GetPushSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, system.NewHTTPError404("Subscription not found")
+		}
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	if existing.Owner != user.ID {
+		return nil, system.NewHTTPError404("Subscription not found")
+	}
+
+	err = s.Store.DeletePushSubscription(ctx, id)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return existing, nil
+}
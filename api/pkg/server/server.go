@@ -112,8 +112,9 @@ func NewServer(
 			authenticator,
 			store,
 			authMiddlewareConfig{
-				adminUserIDs: cfg.WebServer.AdminIDs,
-				runnerToken:  cfg.WebServer.RunnerToken,
+				adminUserIDs:     cfg.WebServer.AdminIDs,
+				runnerToken:      cfg.WebServer.RunnerToken,
+				trustedProxyHops: trustedProxyHops(cfg),
 			},
 		),
 		providerManager:  providerManager,
@@ -123,6 +124,13 @@ func NewServer(
 	}, nil
 }
 
+func trustedProxyHops(cfg *config.ServerConfig) int {
+	if !cfg.WebServer.TrustProxyHeaders {
+		return 0
+	}
+	return cfg.WebServer.TrustedProxyHops
+}
+
 func (apiServer *HelixAPIServer) ListenAndServe(ctx context.Context, _ *system.CleanupManager) error {
 	apiRouter, err := apiServer.registerRoutes(ctx)
 	if err != nil {
@@ -294,6 +302,10 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/sessions/{id}", system.Wrapper(apiServer.updateSession)).Methods(http.MethodPut)
 	authRouter.HandleFunc("/sessions/{id}", system.Wrapper(apiServer.deleteSession)).Methods(http.MethodDelete)
 	authRouter.HandleFunc("/sessions/{id}/restart", system.Wrapper(apiServer.restartSession)).Methods(http.MethodPut)
+	authRouter.HandleFunc("/sessions/{id}/scratch", system.Wrapper(apiServer.listSessionScratch)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/sessions/{id}/scratch/{key:.+}", system.Wrapper(apiServer.putSessionScratch)).Methods(http.MethodPut)
+	authRouter.HandleFunc("/sessions/{id}/scratch/{key:.+}", apiServer.getSessionScratch).Methods(http.MethodGet)
+	authRouter.HandleFunc("/sessions/{id}/scratch/{key:.+}", system.Wrapper(apiServer.deleteSessionScratch)).Methods(http.MethodDelete)
 	authRouter.HandleFunc("/sessions/{id}/config", system.Wrapper(apiServer.updateSessionConfig)).Methods(http.MethodPut)
 
 	authRouter.HandleFunc("/sessions/{id}/meta", system.Wrapper(apiServer.updateSessionMeta)).Methods(http.MethodPut)
@@ -309,6 +321,13 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/secrets/{id}", system.Wrapper(apiServer.updateSecret)).Methods(http.MethodPut)
 	authRouter.HandleFunc("/secrets/{id}", system.Wrapper(apiServer.deleteSecret)).Methods(http.MethodDelete)
 
+	authRouter.HandleFunc("/notifications/web_push", system.Wrapper(apiServer.getWebPushConfig)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/notifications/web_push/subscriptions", system.Wrapper(apiServer.listPushSubscriptions)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/notifications/web_push/subscriptions", system.Wrapper(apiServer.createPushSubscription)).Methods(http.MethodPost)
+	authRouter.HandleFunc("/notifications/web_push/subscriptions/{id}", system.Wrapper(apiServer.deletePushSubscription)).Methods(http.MethodDelete)
+	authRouter.HandleFunc("/notifications/preferences", system.Wrapper(apiServer.getNotificationPreferences)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/notifications/preferences", system.Wrapper(apiServer.updateNotificationPreferences)).Methods(http.MethodPut)
+
 	authRouter.HandleFunc("/apps", system.Wrapper(apiServer.listApps)).Methods(http.MethodGet)
 	authRouter.HandleFunc("/apps", system.Wrapper(apiServer.createApp)).Methods(http.MethodPost)
 	authRouter.HandleFunc("/apps/{id}", system.Wrapper(apiServer.getApp)).Methods(http.MethodGet)
@@ -316,8 +335,17 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/apps/github/{id}", system.Wrapper(apiServer.updateGithubApp)).Methods(http.MethodPut)
 	authRouter.HandleFunc("/apps/{id}", system.Wrapper(apiServer.deleteApp)).Methods(http.MethodDelete)
 	authRouter.HandleFunc("/apps/{id}/llm-calls", system.Wrapper(apiServer.listAppLLMCalls)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/apps/{id}/analytics", system.Wrapper(apiServer.getAppAnalytics)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/apps/{id}/memories", system.Wrapper(apiServer.listAgentMemories)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/apps/{id}/memories", system.Wrapper(apiServer.createAgentMemory)).Methods(http.MethodPost)
+	authRouter.HandleFunc("/apps/{id}/memories/{memory_id}", system.Wrapper(apiServer.updateAgentMemory)).Methods(http.MethodPut)
+	authRouter.HandleFunc("/apps/{id}/memories/{memory_id}", system.Wrapper(apiServer.deleteAgentMemory)).Methods(http.MethodDelete)
 	authRouter.HandleFunc("/apps/{id}/api-actions", system.Wrapper(apiServer.appRunAPIAction)).Methods(http.MethodPost)
 
+	authRouter.HandleFunc("/agents/pause", system.Wrapper(apiServer.getAgentPauseStatus)).Methods(http.MethodGet)
+	authRouter.HandleFunc("/agents/pause", system.Wrapper(apiServer.pauseAgents)).Methods(http.MethodPost)
+	authRouter.HandleFunc("/agents/pause", system.Wrapper(apiServer.resumeAgents)).Methods(http.MethodDelete)
+
 	authRouter.HandleFunc("/search", system.Wrapper(apiServer.knowledgeSearch)).Methods(http.MethodGet)
 
 	authRouter.HandleFunc("/knowledge", system.Wrapper(apiServer.listKnowledge)).Methods(http.MethodGet)
@@ -333,6 +361,7 @@ func (apiServer *HelixAPIServer) registerRoutes(_ context.Context) (*mux.Router,
 	authRouter.HandleFunc("/apps/script", system.Wrapper(apiServer.appRunScript)).Methods(http.MethodPost, http.MethodOptions)
 	adminRouter.HandleFunc("/dashboard", system.DefaultWrapper(apiServer.dashboard)).Methods(http.MethodGet)
 	adminRouter.HandleFunc("/llm_calls", system.Wrapper(apiServer.listLLMCalls)).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/session_archive", system.Wrapper(apiServer.getSessionArchiveStats)).Methods(http.MethodGet)
 
 	// all these routes are secured via runner tokens
 	runnerRouter.HandleFunc("/runner/{runnerid}/nextsession", system.DefaultWrapper(apiServer.getNextRunnerSession)).Methods(http.MethodGet)
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/helixml/helix/api/pkg/sessionarchive"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// getSessionArchiveStats godoc
+// @Summary Get session archive stats
+// @Description Get how many sessions were archived, the database space saved and how often archived sessions are rehydrated
+// @Tags    sessions
+// @Produce json
+// @Success 200 {object} types.SessionArchiveStats
+// @Router /api/v1/admin/session_archive [get]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) getSessionArchiveStats(_ http.ResponseWriter, _ *http.Request) (*types.SessionArchiveStats, *system.HTTPError) {
+	archiveStore, ok := apiServer.Store.(*sessionarchive.Store)
+	if !ok {
+		return nil, system.NewHTTPError404("session archive is not enabled")
+	}
+
+	return archiveStore.Archiver().Stats(), nil
+}
@@ -475,7 +475,7 @@ func (s *HelixAPIServer) handleBlockingSession(
 			return fmt.Errorf("error writing session: %w", writeErr)
 		}
 
-		http.Error(rw, fmt.Sprintf("error running LLM: %s", err.Error()), http.StatusInternalServerError)
+		http.Error(rw, fmt.Sprintf("error running LLM: %s", err.Error()), chatCompletionErrorStatus(err))
 		return nil
 	}
 
@@ -494,6 +494,8 @@ func (s *HelixAPIServer) handleBlockingSession(
 		return err
 	}
 
+	s.Controller.NotifySessionComplete(ctx, session)
+
 	chatCompletionResponse.ID = session.ID
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -541,7 +543,7 @@ func (s *HelixAPIServer) handleStreamingSession(ctx context.Context, user *types
 			log.Error().Err(err).Msg("failed to write session")
 		}
 
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		http.Error(rw, err.Error(), chatCompletionErrorStatus(err))
 		return nil
 	}
 	defer stream.Close()
@@ -584,5 +586,11 @@ func (s *HelixAPIServer) handleStreamingSession(ctx context.Context, user *types
 	session.Interactions[len(session.Interactions)-1].State = types.InteractionStateComplete
 	session.Interactions[len(session.Interactions)-1].Finished = true
 
-	return s.Controller.WriteSession(ctx, session)
+	if err := s.Controller.WriteSession(ctx, session); err != nil {
+		return err
+	}
+
+	s.Controller.NotifySessionComplete(ctx, session)
+
+	return nil
 }
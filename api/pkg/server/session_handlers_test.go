@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/extract"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/janitor"
+	"github.com/helixml/helix/api/pkg/notification"
+	"github.com/helixml/helix/api/pkg/openai"
+	"github.com/helixml/helix/api/pkg/openai/manager"
+	"github.com/helixml/helix/api/pkg/pubsub"
+	"github.com/helixml/helix/api/pkg/scheduler"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type recordingNotifier struct {
+	notifications []*notification.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification *notification.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func newSessionTestServer(t *testing.T, notifier notification.Notifier) (*HelixAPIServer, *openai.MockClient) {
+	ctrl := gomock.NewController(t)
+
+	storeMock := store.NewMockStore(ctrl)
+	storeMock.EXPECT().GetAgentPause(gomock.Any(), gomock.Any()).Return(nil, store.ErrNotFound).AnyTimes()
+	storeMock.EXPECT().ListAgentMemories(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	storeMock.EXPECT().UpdateSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, session types.Session) (*types.Session, error) {
+			return &session, nil
+		})
+
+	ps, err := pubsub.New(t.TempDir())
+	require.NoError(t, err)
+
+	openAiClient := openai.NewMockClient(ctrl)
+
+	providerManager := manager.NewMockProviderManager(ctrl)
+	providerManager.EXPECT().GetClient(gomock.Any(), gomock.Any()).Return(openAiClient, nil).AnyTimes()
+
+	cfg := &config.ServerConfig{}
+	cfg.Inference.Provider = types.ProviderTogetherAI
+
+	c, err := controller.NewController(context.Background(), controller.Options{
+		Config:          cfg,
+		Store:           storeMock,
+		Janitor:         janitor.NewJanitor(config.Janitor{}),
+		Notifier:        notifier,
+		ProviderManager: providerManager,
+		Filestore:       filestore.NewMockFileStore(ctrl),
+		Extractor:       extract.NewMockExtractor(ctrl),
+		Scheduler:       scheduler.NewScheduler(context.Background(), cfg, nil),
+		PubSub:          ps,
+	})
+	require.NoError(t, err)
+
+	return &HelixAPIServer{
+		Cfg:        cfg,
+		pubsub:     ps,
+		Controller: c,
+		Store:      storeMock,
+	}, openAiClient
+}
+
+func newTestChatSession() *types.Session {
+	return &types.Session{
+		ID:    "ses_1",
+		Owner: "user_id",
+		Mode:  types.SessionModeInference,
+		Interactions: []*types.Interaction{
+			{ID: "i1", Creator: types.CreatorTypeUser, Message: "tell me about oceans!"},
+			{ID: "i2", Creator: types.CreatorTypeAssistant, State: types.InteractionStateWaiting},
+		},
+	}
+}
+
+func TestHandleBlockingSession_NotifiesSessionComplete(t *testing.T) {
+	notifier := &recordingNotifier{}
+	server, openAiClient := newSessionTestServer(t, notifier)
+
+	openAiClient.EXPECT().CreateChatCompletion(gomock.Any(), gomock.Any()).Return(oai.ChatCompletionResponse{
+		Choices: []oai.ChatCompletionChoice{
+			{Message: oai.ChatCompletionMessage{Role: "assistant", Content: "oceans are big"}},
+		},
+	}, nil)
+
+	user := &types.User{ID: "user_id"}
+	rec := httptest.NewRecorder()
+
+	err := server.handleBlockingSession(context.Background(), user, newTestChatSession(), oai.ChatCompletionRequest{
+		Messages: []oai.ChatCompletionMessage{{Role: "user", Content: "tell me about oceans!"}},
+	}, &controller.ChatCompletionOptions{}, rec)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, notifier.notifications, 1)
+	require.Equal(t, notification.EventSessionComplete, notifier.notifications[0].Event)
+	require.Equal(t, "ses_1", notifier.notifications[0].Session.ID)
+	require.Equal(t, "oceans are big", notifier.notifications[0].Session.Interactions[1].Message)
+}
+
+func TestHandleStreamingSession_NotifiesSessionComplete(t *testing.T) {
+	notifier := &recordingNotifier{}
+	server, openAiClient := newSessionTestServer(t, notifier)
+
+	stream, writer, err := openai.NewOpenAIStreamingAdapter(oai.ChatCompletionRequest{})
+	require.NoError(t, err)
+
+	openAiClient.EXPECT().CreateChatCompletionStream(gomock.Any(), gomock.Any()).Return(stream, nil)
+
+	go func() {
+		bts, err := json.Marshal(oai.ChatCompletionStreamResponse{
+			Object: "chat.completion.chunk",
+			Choices: []oai.ChatCompletionStreamChoice{
+				{Delta: oai.ChatCompletionStreamChoiceDelta{Role: "assistant", Content: "oceans are big"}},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, writeChunk(writer, bts))
+
+		_, err = writer.Write([]byte("[DONE]"))
+		require.NoError(t, err)
+
+		writer.Close()
+	}()
+
+	user := &types.User{ID: "user_id"}
+	rec := httptest.NewRecorder()
+
+	err = server.handleStreamingSession(context.Background(), user, newTestChatSession(), oai.ChatCompletionRequest{
+		Messages: []oai.ChatCompletionMessage{{Role: "user", Content: "tell me about oceans!"}},
+	}, &controller.ChatCompletionOptions{}, rec)
+	require.NoError(t, err)
+
+	require.Len(t, notifier.notifications, 1)
+	require.Equal(t, notification.EventSessionComplete, notifier.notifications[0].Event)
+	require.Equal(t, "oceans are big", notifier.notifications[0].Session.Interactions[1].Message)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/controller"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// listSessionScratch godoc
+// @Summary List session scratch objects
+// @Description List the temporary objects stored in the session's scratch space. Objects are deleted with the session or once they expire.
+// @Tags    sessions
+// @Success 200 {array} types.SessionScratchObject
+// @Param id path string true "Session ID"
+// @Router /api/v1/sessions/{id}/scratch [get]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) listSessionScratch(_ http.ResponseWriter, req *http.Request) ([]*types.SessionScratchObject, *system.HTTPError) {
+	session, httpError := apiServer.sessionLoader(req, false)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	objs, err := apiServer.Controller.ListSessionScratchObjects(req.Context(), session)
+	if err != nil {
+		return nil, system.NewHTTPError500(err.Error())
+	}
+
+	return objs, nil
+}
+
+// putSessionScratch godoc
+// @Summary Write a session scratch object
+// @Description Store the request body under the given key in the session's scratch space, replacing any existing object. Writing an object resets its expiry.
+// @Tags    sessions
+// @Accept  */*
+// @Success 200 {object} types.SessionScratchObject
+// @Param id path string true "Session ID"
+// @Param key path string true "Object key, may contain slashes"
+// @Router /api/v1/sessions/{id}/scratch/{key} [put]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) putSessionScratch(_ http.ResponseWriter, req *http.Request) (*types.SessionScratchObject, *system.HTTPError) {
+	session, httpError := apiServer.sessionLoader(req, true)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	obj, err := apiServer.Controller.PutSessionScratchObject(req.Context(), session, mux.Vars(req)["key"], req.Header.Get("Content-Type"), req.Body)
+	if err != nil {
+		return nil, sessionScratchError(err)
+	}
+
+	return obj, nil
+}
+
+// getSessionScratch godoc
+// @Summary Read a session scratch object
+// @Description Download the content of a scratch object.
+// @Tags    sessions
+// @Produce */*
+// @Success 200
+// @Param id path string true "Session ID"
+// @Param key path string true "Object key, may contain slashes"
+// @Router /api/v1/sessions/{id}/scratch/{key} [get]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) getSessionScratch(res http.ResponseWriter, req *http.Request) {
+	session, httpError := apiServer.sessionLoader(req, false)
+	if httpError != nil {
+		http.Error(res, httpError.Message, httpError.StatusCode)
+		return
+	}
+
+	obj, reader, err := apiServer.Controller.GetSessionScratchObject(req.Context(), session, mux.Vars(req)["key"])
+	if err != nil {
+		httpError := sessionScratchError(err)
+		http.Error(res, httpError.Message, httpError.StatusCode)
+		return
+	}
+	defer reader.Close()
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+
+	if _, err := io.Copy(res, reader); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Str("key", obj.Key).Msg("failed to write scratch object")
+	}
+}
+
+// deleteSessionScratch godoc
+// @Summary Delete a session scratch object
+// @Tags    sessions
+// @Success 200
+// @Param id path string true "Session ID"
+// @Param key path string true "Object key, may contain slashes"
+// @Router /api/v1/sessions/{id}/scratch/{key} [delete]
+// @Security BearerAuth
+func (apiServer *HelixAPIServer) deleteSessionScratch(_ http.ResponseWriter, req *http.Request) (string, *system.HTTPError) {
+	session, httpError := apiServer.sessionLoader(req, true)
+	if httpError != nil {
+		return "", httpError
+	}
+
+	key := mux.Vars(req)["key"]
+	if err := apiServer.Controller.DeleteSessionScratchObject(req.Context(), session, key); err != nil {
+		return "", sessionScratchError(err)
+	}
+
+	return key, nil
+}
+
+func sessionScratchError(err error) *system.HTTPError {
+	switch {
+	case errors.Is(err, controller.ErrScratchKeyInvalid):
+		return system.NewHTTPError400(fmt.Sprintf("%s: keys must be relative paths of letters, digits, '.', '_', '-' and '/'", err.Error()))
+	case errors.Is(err, controller.ErrScratchKeyConflict):
+		return &system.HTTPError{StatusCode: http.StatusConflict, Message: err.Error()}
+	case errors.Is(err, controller.ErrScratchObjectTooBig), errors.Is(err, controller.ErrScratchQuotaExceeded):
+		return &system.HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: err.Error()}
+	case errors.Is(err, store.ErrNotFound):
+		return system.NewHTTPError404("scratch object not found")
+	default:
+		return system.NewHTTPError500(err.Error())
+	}
+}
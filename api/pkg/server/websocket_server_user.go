@@ -41,6 +41,13 @@ func (apiServer *HelixAPIServer) startUserWebSocketServer(
 			return
 		}
 
+		if user.APIKey != nil {
+			if err := apiServer.authMiddleware.authorizeAPIKey(r, user.APIKey); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+
 		sessionID := r.URL.Query().Get("session_id")
 		if sessionID == "" {
 			log.Error().Msgf("No session_id supplied")
@@ -0,0 +1,264 @@
+package sessionarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/data"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// summaryMessageLength is how much of the prompt is kept in the database so
+// session lists still show what the session was about
+const summaryMessageLength = 500
+
+// Archiver moves the interactions of sessions that haven't been updated within
+// the retention period into gzipped JSON objects in the filestore, leaving a
+// summary of the last exchange in Postgres
+type Archiver struct {
+	cfg       config.SessionArchive
+	basePath  string
+	store     store.Store
+	filestore filestore.FileStore
+	now       func() time.Time
+
+	archivedSessions  atomic.Int64
+	archivedBytes     atomic.Int64
+	savedBytes        atomic.Int64
+	lookups           atomic.Int64
+	rehydrations      atomic.Int64
+	rehydrationErrors atomic.Int64
+}
+
+func New(cfg *config.ServerConfig, store store.Store, fs filestore.FileStore) (*Archiver, error) {
+	if cfg.SessionArchive.Retention <= 0 {
+		return nil, fmt.Errorf("session archive retention must be positive")
+	}
+
+	if cfg.SessionArchive.BatchSize <= 0 {
+		return nil, fmt.Errorf("session archive batch size must be positive")
+	}
+
+	return &Archiver{
+		cfg:       cfg.SessionArchive,
+		basePath:  filepath.Join(cfg.Controller.FilePrefixGlobal, cfg.SessionArchive.Path),
+		store:     store,
+		filestore: fs,
+		now:       time.Now,
+	}, nil
+}
+
+// Start archives sessions immediately and then on every configured interval
+// until the context is cancelled
+func (a *Archiver) Start(ctx context.Context) {
+	for {
+		archived, err := a.Archive(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("session archive failed")
+		}
+
+		stats := a.Stats()
+		log.Info().
+			Int("archived", archived).
+			Int64("total_archived", stats.ArchivedSessions).
+			Int64("saved_bytes", stats.SavedBytes).
+			Float64("hit_rate", stats.HitRate).
+			Msg("session archive complete")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.cfg.Interval):
+		}
+	}
+}
+
+// Archive archives up to one batch of sessions past the retention period and
+// returns how many were archived
+func (a *Archiver) Archive(ctx context.Context) (int, error) {
+	sessions, err := a.store.ListSessionsForArchive(ctx, &store.ListSessionsForArchiveQuery{
+		UpdatedBefore: a.now().Add(-a.cfg.Retention),
+		Limit:         a.cfg.BatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	archived := 0
+	for _, session := range sessions {
+		err := a.archiveSession(ctx, session)
+		if errors.Is(err, store.ErrNotFound) {
+			// Updated or deleted since it was listed, it will be
+			// picked up again once it is old enough
+			continue
+		}
+		if err != nil {
+			return archived, fmt.Errorf("failed to archive session %s: %w", session.ID, err)
+		}
+		archived++
+	}
+
+	return archived, nil
+}
+
+func (a *Archiver) archiveSession(ctx context.Context, session *types.Session) error {
+	original, err := json.Marshal(session.Interactions)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(original); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	compressed := int64(buf.Len())
+
+	if _, err := a.filestore.WriteFile(ctx, a.objectPath(session.ID), &buf); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	summary := summarizeInteractions(session.Interactions)
+	summaryData, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	err = a.store.ArchiveSession(ctx, &store.ArchiveSessionRequest{
+		ID:           session.ID,
+		Updated:      session.Updated,
+		Interactions: summary,
+		ArchivedAt:   a.now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	a.archivedSessions.Add(1)
+	a.archivedBytes.Add(compressed)
+	a.savedBytes.Add(int64(len(original) - len(summaryData)))
+
+	return nil
+}
+
+// Rehydrate loads the archived interactions back into the session. The
+// session is only changed in memory, the next update writes the interactions
+// back to the database and clears the archived flag. The archive object is
+// left in place and overwritten if the session is archived again.
+func (a *Archiver) Rehydrate(ctx context.Context, session *types.Session) error {
+	a.rehydrations.Add(1)
+
+	interactions, err := a.readArchive(ctx, session.ID)
+	if err != nil {
+		a.rehydrationErrors.Add(1)
+		return fmt.Errorf("failed to rehydrate session %s: %w", session.ID, err)
+	}
+
+	session.Interactions = interactions
+	session.ArchivedAt = nil
+	return nil
+}
+
+func (a *Archiver) readArchive(ctx context.Context, sessionID string) (types.Interactions, error) {
+	r, err := a.filestore.OpenFile(ctx, a.objectPath(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var interactions types.Interactions
+	if err := json.NewDecoder(gz).Decode(&interactions); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// Delete removes the archived interactions of a session
+func (a *Archiver) Delete(ctx context.Context, sessionID string) error {
+	return a.filestore.Delete(ctx, a.objectPath(sessionID))
+}
+
+// Stats returns the archive counters since the server started
+func (a *Archiver) Stats() *types.SessionArchiveStats {
+	stats := &types.SessionArchiveStats{
+		ArchivedSessions:  a.archivedSessions.Load(),
+		ArchivedBytes:     a.archivedBytes.Load(),
+		SavedBytes:        a.savedBytes.Load(),
+		Lookups:           a.lookups.Load(),
+		Rehydrations:      a.rehydrations.Load(),
+		RehydrationErrors: a.rehydrationErrors.Load(),
+	}
+	if stats.Lookups > 0 {
+		stats.HitRate = float64(stats.Rehydrations) / float64(stats.Lookups)
+	}
+	return stats
+}
+
+func (a *Archiver) objectPath(sessionID string) string {
+	return filepath.Join(a.basePath, sessionID+".json.gz")
+}
+
+// summarizeInteractions keeps the last user and assistant interactions with
+// only the fields needed to build a session summary
+func summarizeInteractions(interactions types.Interactions) types.Interactions {
+	summary := types.Interactions{}
+
+	user, err := data.GetUserInteraction(interactions)
+	if err == nil {
+		message := []rune(user.Message)
+		if len(message) > summaryMessageLength {
+			message = message[:summaryMessageLength]
+		}
+		summary = append(summary, &types.Interaction{
+			ID:        user.ID,
+			Created:   user.Created,
+			Updated:   user.Updated,
+			Scheduled: user.Scheduled,
+			Completed: user.Completed,
+			Creator:   user.Creator,
+			Mode:      user.Mode,
+			Message:   string(message),
+			Files:     user.Files,
+			Finished:  user.Finished,
+			State:     user.State,
+		})
+	}
+
+	assistant, err := data.GetLastAssistantInteraction(interactions)
+	if err == nil {
+		summary = append(summary, &types.Interaction{
+			ID:        assistant.ID,
+			Created:   assistant.Created,
+			Updated:   assistant.Updated,
+			Scheduled: assistant.Scheduled,
+			Completed: assistant.Completed,
+			Creator:   assistant.Creator,
+			Mode:      assistant.Mode,
+			Finished:  assistant.Finished,
+			State:     assistant.State,
+		})
+	}
+
+	return summary
+}
@@ -0,0 +1,140 @@
+package sessionarchive
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/helixml/helix/api/pkg/config"
+	"github.com/helixml/helix/api/pkg/filestore"
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+func newTestArchiver(t *testing.T, storeMock store.Store) *Archiver {
+	cfg := &config.ServerConfig{}
+	cfg.Controller.FilePrefixGlobal = "dev"
+	cfg.SessionArchive = config.SessionArchive{
+		Retention: 24 * time.Hour,
+		Path:      "session-archive",
+		BatchSize: 10,
+	}
+
+	fs := filestore.NewFileSystemStorage(t.TempDir(), "http://localhost/files", "secret")
+
+	archiver, err := New(cfg, storeMock, fs)
+	require.NoError(t, err)
+	return archiver
+}
+
+func testInteractions() types.Interactions {
+	return types.Interactions{
+		{ID: "i1", Creator: types.CreatorTypeUser, Message: "first question"},
+		{ID: "i2", Creator: types.CreatorTypeAssistant, Message: "first answer"},
+		{ID: "i3", Creator: types.CreatorTypeUser, Message: strings.Repeat("x", summaryMessageLength+10)},
+		{ID: "i4", Creator: types.CreatorTypeAssistant, Message: "second answer", State: types.InteractionStateComplete},
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	cfg := &config.ServerConfig{}
+	cfg.SessionArchive.BatchSize = 10
+	_, err := New(cfg, nil, nil)
+	require.Error(t, err)
+
+	cfg.SessionArchive.Retention = time.Hour
+	cfg.SessionArchive.BatchSize = 0
+	_, err = New(cfg, nil, nil)
+	require.Error(t, err)
+}
+
+func TestArchiveAndRehydrate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	archiver := newTestArchiver(t, storeMock)
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	archiver.now = func() time.Time { return now }
+
+	updated := now.Add(-48 * time.Hour)
+	storeMock.EXPECT().ListSessionsForArchive(gomock.Any(), &store.ListSessionsForArchiveQuery{
+		UpdatedBefore: now.Add(-24 * time.Hour),
+		Limit:         10,
+	}).Return([]*types.Session{
+		{ID: "ses_1", Updated: updated, Interactions: testInteractions()},
+		{ID: "ses_2", Updated: updated, Interactions: testInteractions()},
+	}, nil)
+
+	var summary types.Interactions
+	storeMock.EXPECT().ArchiveSession(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *store.ArchiveSessionRequest) error {
+			if req.ID == "ses_2" {
+				// Updated since it was listed
+				return store.ErrNotFound
+			}
+			require.Equal(t, updated, req.Updated)
+			require.Equal(t, now, req.ArchivedAt)
+			summary = req.Interactions
+			return nil
+		}).Times(2)
+
+	archived, err := archiver.Archive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	// Only the last exchange is kept, without the assistant response
+	require.Len(t, summary, 2)
+	require.Equal(t, "i3", summary[0].ID)
+	require.Len(t, summary[0].Message, summaryMessageLength)
+	require.Equal(t, "i4", summary[1].ID)
+	require.Empty(t, summary[1].Message)
+	require.Equal(t, types.InteractionStateComplete, summary[1].State)
+
+	archiveStore := NewStore(storeMock, archiver)
+
+	storeMock.EXPECT().GetSession(gomock.Any(), "ses_1").Return(&types.Session{
+		ID:           "ses_1",
+		Interactions: summary,
+		ArchivedAt:   &now,
+	}, nil)
+	storeMock.EXPECT().GetSession(gomock.Any(), "ses_3").Return(&types.Session{
+		ID:           "ses_3",
+		Interactions: testInteractions(),
+	}, nil)
+
+	session, err := archiveStore.GetSession(context.Background(), "ses_1")
+	require.NoError(t, err)
+	require.Nil(t, session.ArchivedAt)
+	require.Equal(t, testInteractions(), session.Interactions)
+
+	_, err = archiveStore.GetSession(context.Background(), "ses_3")
+	require.NoError(t, err)
+
+	stats := archiver.Stats()
+	require.Equal(t, int64(1), stats.ArchivedSessions)
+	require.Positive(t, stats.ArchivedBytes)
+	require.Positive(t, stats.SavedBytes)
+	require.Equal(t, int64(2), stats.Lookups)
+	require.Equal(t, int64(1), stats.Rehydrations)
+	require.Equal(t, 0.5, stats.HitRate)
+}
+
+func TestRehydrate_MissingArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storeMock := store.NewMockStore(ctrl)
+	archiver := newTestArchiver(t, storeMock)
+
+	archivedAt := time.Now()
+	storeMock.EXPECT().GetSession(gomock.Any(), "ses_1").Return(&types.Session{
+		ID:         "ses_1",
+		ArchivedAt: &archivedAt,
+	}, nil)
+
+	_, err := NewStore(storeMock, archiver).GetSession(context.Background(), "ses_1")
+	require.Error(t, err)
+	require.Equal(t, int64(1), archiver.Stats().RehydrationErrors)
+}
@@ -0,0 +1,75 @@
+package sessionarchive
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/helixml/helix/api/pkg/store"
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// Store wraps a store so that archived sessions are transparently rehydrated
+// when they are loaded individually. Session lists keep returning the
+// archived summary.
+type Store struct {
+	store.Store
+	archiver *Archiver
+}
+
+func NewStore(s store.Store, archiver *Archiver) *Store {
+	return &Store{
+		Store:    s,
+		archiver: archiver,
+	}
+}
+
+func (s *Store) Archiver() *Archiver {
+	return s.archiver
+}
+
+func (s *Store) GetSession(ctx context.Context, id string) (*types.Session, error) {
+	session, err := s.Store.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.archiver.lookups.Add(1)
+
+	return s.rehydrate(ctx, session)
+}
+
+func (s *Store) UpdateSessionMeta(ctx context.Context, data types.SessionMetaUpdate) (*types.Session, error) {
+	session, err := s.Store.UpdateSessionMeta(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rehydrate(ctx, session)
+}
+
+func (s *Store) DeleteSession(ctx context.Context, id string) (*types.Session, error) {
+	session, err := s.Store.DeleteSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.ArchivedAt != nil {
+		if err := s.archiver.Delete(ctx, id); err != nil {
+			log.Warn().Err(err).Str("session_id", id).Msg("failed to delete archived session interactions")
+		}
+	}
+
+	return session, nil
+}
+
+func (s *Store) rehydrate(ctx context.Context, session *types.Session) (*types.Session, error) {
+	if session.ArchivedAt == nil {
+		return session, nil
+	}
+
+	if err := s.archiver.Rehydrate(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,2 @@
+ALTER TABLE session
+DROP COLUMN archived_at;
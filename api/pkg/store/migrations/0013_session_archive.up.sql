@@ -0,0 +1,2 @@
+ALTER TABLE session
+ADD COLUMN archived_at timestamp;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS session_archive_candidates_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS session_archive_candidates_idx ON session (updated)
+WHERE archived_at IS NULL;
@@ -110,6 +110,11 @@ func (s *PostgresStore) autoMigrate() error {
 		&types.LLMCall{},
 		&MigrationScript{},
 		&types.Secret{},
+		&types.AgentPause{},
+		&types.SessionScratchObject{},
+		&types.AgentMemory{},
+		&types.PushSubscription{},
+		&types.NotificationPreferences{},
 	)
 	if err != nil {
 		return err
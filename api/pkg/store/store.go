@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/helixml/helix/api/pkg/types"
 )
@@ -78,6 +79,7 @@ type Store interface {
 	GetAPIKey(ctx context.Context, apiKey string) (*types.APIKey, error)
 	ListAPIKeys(ctx context.Context, query *ListAPIKeysQuery) ([]*types.APIKey, error)
 	DeleteAPIKey(ctx context.Context, apiKey string) error
+	UpdateAPIKeyLastUsed(ctx context.Context, apiKey string, usedAt time.Time, ip string) error
 
 	// tools
 	CreateTool(ctx context.Context, tool *types.Tool) (*types.Tool, error)
@@ -132,6 +134,52 @@ type Store interface {
 
 	CreateLLMCall(ctx context.Context, call *types.LLMCall) (*types.LLMCall, error)
 	ListLLMCalls(ctx context.Context, q *ListLLMCallsQuery) ([]*types.LLMCall, int64, error)
+
+	// analytics
+	ListAppSessions(ctx context.Context, q *ListAppSessionsQuery) ([]*types.Session, error)
+	SumLLMCallTokensBySession(ctx context.Context, q *ListAppSessionsQuery) (map[string]int64, error)
+
+	// data export
+	ListLLMCallsForExport(ctx context.Context, q *ExportQuery) ([]*types.LLMCall, error)
+	ListSessionsForExport(ctx context.Context, q *ExportQuery) ([]*types.Session, error)
+	ListScriptRunsForExport(ctx context.Context, q *ExportQuery) ([]*types.ScriptRun, error)
+
+	// session archive
+	ListSessionsForArchive(ctx context.Context, q *ListSessionsForArchiveQuery) ([]*types.Session, error)
+	ArchiveSession(ctx context.Context, req *ArchiveSessionRequest) error
+
+	// agent pauses
+	CreateAgentPause(ctx context.Context, pause *types.AgentPause) (*types.AgentPause, error)
+	GetAgentPause(ctx context.Context, id string) (*types.AgentPause, error)
+	DeleteAgentPause(ctx context.Context, id string) error
+
+	// session scratch objects
+	CreateSessionScratchObject(ctx context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error)
+	GetSessionScratchObject(ctx context.Context, sessionID, key string) (*types.SessionScratchObject, error)
+	ListSessionScratchObjects(ctx context.Context, sessionID string) ([]*types.SessionScratchObject, error)
+	ListExpiredSessionScratchObjects(ctx context.Context, now time.Time) ([]*types.SessionScratchObject, error)
+	DeleteSessionScratchObject(ctx context.Context, sessionID, key string) error
+
+	// agent memories
+	CreateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error)
+	UpdateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error)
+	GetAgentMemory(ctx context.Context, id string) (*types.AgentMemory, error)
+	ListAgentMemories(ctx context.Context, q *ListAgentMemoriesQuery) ([]*types.AgentMemory, error)
+	DeleteAgentMemory(ctx context.Context, id string) error
+
+	// push subscriptions
+	CreatePushSubscription(ctx context.Context, sub *types.PushSubscription) (*types.PushSubscription, error)
+	GetPushSubscription(ctx context.Context, id string) (*types.PushSubscription, error)
+	ListPushSubscriptions(ctx context.Context, owner string) ([]*types.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, id string) error
+
+	// notification preferences
+	GetNotificationPreferences(ctx context.Context, owner string) (*types.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, prefs *types.NotificationPreferences) (*types.NotificationPreferences, error)
+
+	// cross-replica locks
+	TryAcquireLock(ctx context.Context, key string) (release func(), ok bool, err error)
+	AcquireLock(ctx context.Context, key string) (release func(), err error)
 }
 
 var ErrNotFound = errors.New("not found")
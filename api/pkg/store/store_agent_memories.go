@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+)
+
+type ListAgentMemoriesQuery struct {
+	AppID     string
+	Owner     string
+	OwnerType types.OwnerType
+}
+
+func (s *PostgresStore) CreateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error) {
+	if memory.ID == "" {
+		memory.ID = system.GenerateAgentMemoryID()
+	}
+
+	if memory.AppID == "" {
+		return nil, fmt.Errorf("app id not specified")
+	}
+
+	if memory.Owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	memory.Created = time.Now()
+	memory.Updated = memory.Created
+
+	err := s.gdb.WithContext(ctx).Create(memory).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAgentMemory(ctx, memory.ID)
+}
+
+func (s *PostgresStore) UpdateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error) {
+	if memory.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	memory.Updated = time.Now()
+
+	err := s.gdb.WithContext(ctx).Save(memory).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAgentMemory(ctx, memory.ID)
+}
+
+func (s *PostgresStore) GetAgentMemory(ctx context.Context, id string) (*types.AgentMemory, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	var memory types.AgentMemory
+	err := s.gdb.WithContext(ctx).Where("id = ?", id).First(&memory).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &memory, nil
+}
+
+func (s *PostgresStore) ListAgentMemories(ctx context.Context, q *ListAgentMemoriesQuery) ([]*types.AgentMemory, error) {
+	// Memories are only ever listed for one user of one app, struct conditions
+	// would drop empty fields and match other users' memories
+	if q.AppID == "" {
+		return nil, fmt.Errorf("app id not specified")
+	}
+	if q.Owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	var memories []*types.AgentMemory
+	err := s.gdb.WithContext(ctx).
+		Where("app_id = ? AND owner = ? AND owner_type = ?", q.AppID, q.Owner, q.OwnerType).
+		Order("created ASC").
+		Find(&memories).Error
+	if err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+func (s *PostgresStore) DeleteAgentMemory(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.AgentMemory{
+		ID: id,
+	}).Error
+}
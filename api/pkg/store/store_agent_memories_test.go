@@ -0,0 +1,50 @@
+package store
+
+import (
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *PostgresStoreTestSuite) TestAgentMemoryList() {
+	appID := "app-" + system.GenerateUUID()
+	owner := "test-owner-" + system.GenerateUUID()
+	otherOwner := "test-owner-" + system.GenerateUUID()
+
+	memories := []*types.AgentMemory{
+		{AppID: appID, Owner: owner, OwnerType: types.OwnerTypeUser, Content: "uses pnpm"},
+		{AppID: appID, Owner: owner, OwnerType: types.OwnerTypeUser, Content: "tests live next to the code"},
+		{AppID: appID, Owner: otherOwner, OwnerType: types.OwnerTypeUser, Content: "someone else's note"},
+	}
+
+	for _, m := range memories {
+		_, err := suite.db.CreateAgentMemory(suite.ctx, m)
+		require.NoError(suite.T(), err)
+	}
+
+	suite.T().Cleanup(func() {
+		for _, m := range memories {
+			err := suite.db.DeleteAgentMemory(suite.ctx, m.ID)
+			assert.NoError(suite.T(), err)
+		}
+	})
+
+	listed, err := suite.db.ListAgentMemories(suite.ctx, &ListAgentMemoriesQuery{
+		AppID:     appID,
+		Owner:     owner,
+		OwnerType: types.OwnerTypeUser,
+	})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), listed, 2)
+	for _, m := range listed {
+		assert.Equal(suite.T(), owner, m.Owner)
+	}
+
+	// Empty keys must not turn into "every row"
+	_, err = suite.db.ListAgentMemories(suite.ctx, &ListAgentMemoriesQuery{AppID: appID})
+	require.Error(suite.T(), err)
+
+	_, err = suite.db.ListAgentMemories(suite.ctx, &ListAgentMemoriesQuery{Owner: owner})
+	require.Error(suite.T(), err)
+}
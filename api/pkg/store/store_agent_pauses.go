@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateAgentPause creates or replaces the pause with the given ID
+func (s *PostgresStore) CreateAgentPause(ctx context.Context, pause *types.AgentPause) (*types.AgentPause, error) {
+	if pause.ID == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	pause.Created = time.Now()
+
+	err := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(pause).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAgentPause(ctx, pause.ID)
+}
+
+func (s *PostgresStore) GetAgentPause(ctx context.Context, id string) (*types.AgentPause, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	var pause types.AgentPause
+	err := s.gdb.WithContext(ctx).Where("id = ?", id).First(&pause).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &pause, nil
+}
+
+func (s *PostgresStore) DeleteAgentPause(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.AgentPause{
+		ID: id,
+	}).Error
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type ListAppSessionsQuery struct {
+	AppID string
+	From  time.Time
+	To    time.Time
+
+	// keyset paging, sessions are returned in (created, id) order strictly
+	// after this position
+	AfterCreated time.Time
+	AfterID      string
+	Limit        int
+}
+
+// ListAppSessions returns sessions spawned from the app (by any user) created in the [From, To) range,
+// with only the fields needed for analytics
+func (s *PostgresStore) ListAppSessions(ctx context.Context, q *ListAppSessionsQuery) ([]*types.Session, error) {
+	if q.AppID == "" {
+		return nil, fmt.Errorf("app id not specified")
+	}
+
+	query := s.gdb.WithContext(ctx).
+		Select("id", "created", "updated", "interactions", "archived_at").
+		Where("parent_app = ? AND created >= ? AND created < ?", q.AppID, q.From, q.To)
+
+	if q.AfterID != "" {
+		query = query.Where("(created, id) > (?, ?)", q.AfterCreated, q.AfterID)
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	var sessions []*types.Session
+	err := query.
+		Order("created ASC, id ASC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// SumLLMCallTokensBySession returns the total tokens used by the app's LLM calls in the
+// [From, To) range, keyed by session ID
+func (s *PostgresStore) SumLLMCallTokensBySession(ctx context.Context, q *ListAppSessionsQuery) (map[string]int64, error) {
+	if q.AppID == "" {
+		return nil, fmt.Errorf("app id not specified")
+	}
+
+	var rows []struct {
+		SessionID   string
+		TotalTokens int64
+	}
+
+	err := s.gdb.WithContext(ctx).
+		Model(&types.LLMCall{}).
+		Select("session_id, SUM(total_tokens) AS total_tokens").
+		Where("app_id = ? AND created >= ? AND created < ?", q.AppID, q.From, q.To).
+		Group("session_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		tokens[row.SessionID] = row.TotalTokens
+	}
+	return tokens, nil
+}
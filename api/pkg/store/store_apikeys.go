@@ -73,3 +73,14 @@ func (s *PostgresStore) DeleteAPIKey(ctx context.Context, key string) error {
 
 	return nil
 }
+
+func (s *PostgresStore) UpdateAPIKeyLastUsed(ctx context.Context, key string, usedAt time.Time, ip string) error {
+	if key == "" {
+		return fmt.Errorf("key not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Model(&types.APIKey{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	}).Error
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+// ExportQuery pages through a table in (timestamp, id) order so that an export
+// can resume from the last row it wrote
+type ExportQuery struct {
+	AfterTime time.Time
+	AfterID   string
+	Limit     int
+}
+
+// ListLLMCallsForExport returns LLM calls created after the query position, without
+// the request and response bodies
+func (s *PostgresStore) ListLLMCallsForExport(ctx context.Context, q *ExportQuery) ([]*types.LLMCall, error) {
+	var calls []*types.LLMCall
+	err := s.gdb.WithContext(ctx).
+		Omit("original_request", "request", "response").
+		Where("(created, id) > (?, ?)", q.AfterTime, q.AfterID).
+		Order("created ASC, id ASC").
+		Limit(q.Limit).
+		Find(&calls).Error
+	if err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// ListSessionsForExport returns sessions updated after the query position
+func (s *PostgresStore) ListSessionsForExport(ctx context.Context, q *ExportQuery) ([]*types.Session, error) {
+	var sessions []*types.Session
+	err := s.gdb.WithContext(ctx).
+		Where("(updated, id) > (?, ?)", q.AfterTime, q.AfterID).
+		Order("updated ASC, id ASC").
+		Limit(q.Limit).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListScriptRunsForExport returns script runs created after the query position,
+// without the request and response bodies
+func (s *PostgresStore) ListScriptRunsForExport(ctx context.Context, q *ExportQuery) ([]*types.ScriptRun, error) {
+	var runs []*types.ScriptRun
+	err := s.gdb.WithContext(ctx).
+		Omit("request", "response").
+		Where("(created, id) > (?, ?)", q.AfterTime, q.AfterID).
+		Order("created ASC, id ASC").
+		Limit(q.Limit).
+		Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
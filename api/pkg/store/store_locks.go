@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TryAcquireLock takes a Postgres advisory lock named by key without waiting,
+// so that work which must only run on one API replica at a time can be skipped
+// by the others. The lock is held on its own connection until release is
+// called, or the connection is lost. ok is false if another holder has it.
+func (s *PostgresStore) TryAcquireLock(ctx context.Context, key string) (release func(), ok bool, err error) {
+	conn, err := s.pgDb.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection for lock %s: %w", key, err)
+	}
+
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&ok)
+	if err != nil {
+		discardConn(conn)
+		return nil, false, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return advisoryUnlock(conn, key), true, nil
+}
+
+// AcquireLock takes a Postgres advisory lock named by key, waiting until it is
+// free or the context is cancelled. It serializes work on the same resource
+// across API replicas, the lock is held until release is called.
+func (s *PostgresStore) AcquireLock(ctx context.Context, key string) (release func(), err error) {
+	conn, err := s.pgDb.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for lock %s: %w", key, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", key)
+	if err != nil {
+		// a cancelled wait may still have taken the lock
+		discardConn(conn)
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+
+	return advisoryUnlock(conn, key), nil
+}
+
+func advisoryUnlock(conn *sql.Conn, key string) func() {
+	return func() {
+		// unlock even if the holder's context was cancelled
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key)
+		if err != nil {
+			log.Error().Err(err).Str("lock", key).Msg("failed to release lock")
+			discardConn(conn)
+			return
+		}
+		conn.Close()
+	}
+}
+
+// discardConn closes the connection instead of returning it to the pool, the
+// server releases any advisory locks it may still hold
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
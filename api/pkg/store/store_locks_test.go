@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+func (suite *PostgresStoreTestSuite) TestTryAcquireLock() {
+	release, ok, err := suite.db.TryAcquireLock(suite.ctx, "test-lock")
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+
+	// held on its own connection, so the next attempt fails even from the same store
+	_, ok, err = suite.db.TryAcquireLock(suite.ctx, "test-lock")
+	suite.Require().NoError(err)
+	suite.False(ok)
+
+	release()
+
+	release, ok, err = suite.db.TryAcquireLock(suite.ctx, "test-lock")
+	suite.Require().NoError(err)
+	suite.True(ok)
+	release()
+}
+
+func (suite *PostgresStoreTestSuite) TestAcquireLock() {
+	release, err := suite.db.AcquireLock(suite.ctx, "test-lock")
+	suite.Require().NoError(err)
+
+	// a second holder waits until the context gives up
+	ctx, cancel := context.WithTimeout(suite.ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = suite.db.AcquireLock(ctx, "test-lock")
+	suite.Error(err)
+
+	release()
+
+	release, err = suite.db.AcquireLock(suite.ctx, "test-lock")
+	suite.Require().NoError(err)
+	release()
+}
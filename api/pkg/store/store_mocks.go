@@ -12,6 +12,7 @@ package store
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/helixml/helix/api/pkg/types"
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +42,35 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcquireLock mocks base method.
+func (m *MockStore) AcquireLock(ctx context.Context, key string) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireLock", ctx, key)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireLock indicates an expected call of AcquireLock.
+func (mr *MockStoreMockRecorder) AcquireLock(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireLock", reflect.TypeOf((*MockStore)(nil).AcquireLock), ctx, key)
+}
+
+// ArchiveSession mocks base method.
+func (m *MockStore) ArchiveSession(ctx context.Context, req *ArchiveSessionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveSession", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveSession indicates an expected call of ArchiveSession.
+func (mr *MockStoreMockRecorder) ArchiveSession(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveSession", reflect.TypeOf((*MockStore)(nil).ArchiveSession), ctx, req)
+}
+
 // CreateAPIKey mocks base method.
 func (m *MockStore) CreateAPIKey(ctx context.Context, apiKey *types.APIKey) (*types.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +86,36 @@ func (mr *MockStoreMockRecorder) CreateAPIKey(ctx, apiKey any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), ctx, apiKey)
 }
 
+// CreateAgentMemory mocks base method.
+func (m *MockStore) CreateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAgentMemory", ctx, memory)
+	ret0, _ := ret[0].(*types.AgentMemory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAgentMemory indicates an expected call of CreateAgentMemory.
+func (mr *MockStoreMockRecorder) CreateAgentMemory(ctx, memory any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAgentMemory", reflect.TypeOf((*MockStore)(nil).CreateAgentMemory), ctx, memory)
+}
+
+// CreateAgentPause mocks base method.
+func (m *MockStore) CreateAgentPause(ctx context.Context, pause *types.AgentPause) (*types.AgentPause, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAgentPause", ctx, pause)
+	ret0, _ := ret[0].(*types.AgentPause)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAgentPause indicates an expected call of CreateAgentPause.
+func (mr *MockStoreMockRecorder) CreateAgentPause(ctx, pause any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAgentPause", reflect.TypeOf((*MockStore)(nil).CreateAgentPause), ctx, pause)
+}
+
 // CreateApp mocks base method.
 func (m *MockStore) CreateApp(ctx context.Context, tool *types.App) (*types.App, error) {
 	m.ctrl.T.Helper()
@@ -131,6 +191,21 @@ func (mr *MockStoreMockRecorder) CreateLLMCall(ctx, call any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLLMCall", reflect.TypeOf((*MockStore)(nil).CreateLLMCall), ctx, call)
 }
 
+// CreatePushSubscription mocks base method.
+func (m *MockStore) CreatePushSubscription(ctx context.Context, sub *types.PushSubscription) (*types.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePushSubscription", ctx, sub)
+	ret0, _ := ret[0].(*types.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePushSubscription indicates an expected call of CreatePushSubscription.
+func (mr *MockStoreMockRecorder) CreatePushSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePushSubscription", reflect.TypeOf((*MockStore)(nil).CreatePushSubscription), ctx, sub)
+}
+
 // CreateScriptRun mocks base method.
 func (m *MockStore) CreateScriptRun(ctx context.Context, task *types.ScriptRun) (*types.ScriptRun, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +251,21 @@ func (mr *MockStoreMockRecorder) CreateSession(ctx, session any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), ctx, session)
 }
 
+// CreateSessionScratchObject mocks base method.
+func (m *MockStore) CreateSessionScratchObject(ctx context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSessionScratchObject", ctx, obj)
+	ret0, _ := ret[0].(*types.SessionScratchObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSessionScratchObject indicates an expected call of CreateSessionScratchObject.
+func (mr *MockStoreMockRecorder) CreateSessionScratchObject(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSessionScratchObject", reflect.TypeOf((*MockStore)(nil).CreateSessionScratchObject), ctx, obj)
+}
+
 // CreateSessionToolBinding mocks base method.
 func (m *MockStore) CreateSessionToolBinding(ctx context.Context, sessionID, toolID string) error {
 	m.ctrl.T.Helper()
@@ -234,6 +324,34 @@ func (mr *MockStoreMockRecorder) DeleteAPIKey(ctx, apiKey any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockStore)(nil).DeleteAPIKey), ctx, apiKey)
 }
 
+// DeleteAgentMemory mocks base method.
+func (m *MockStore) DeleteAgentMemory(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAgentMemory", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAgentMemory indicates an expected call of DeleteAgentMemory.
+func (mr *MockStoreMockRecorder) DeleteAgentMemory(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAgentMemory", reflect.TypeOf((*MockStore)(nil).DeleteAgentMemory), ctx, id)
+}
+
+// DeleteAgentPause mocks base method.
+func (m *MockStore) DeleteAgentPause(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAgentPause", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAgentPause indicates an expected call of DeleteAgentPause.
+func (mr *MockStoreMockRecorder) DeleteAgentPause(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAgentPause", reflect.TypeOf((*MockStore)(nil).DeleteAgentPause), ctx, id)
+}
+
 // DeleteApp mocks base method.
 func (m *MockStore) DeleteApp(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -290,6 +408,20 @@ func (mr *MockStoreMockRecorder) DeleteKnowledgeVersion(ctx, id any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKnowledgeVersion", reflect.TypeOf((*MockStore)(nil).DeleteKnowledgeVersion), ctx, id)
 }
 
+// DeletePushSubscription mocks base method.
+func (m *MockStore) DeletePushSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePushSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushSubscription indicates an expected call of DeletePushSubscription.
+func (mr *MockStoreMockRecorder) DeletePushSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushSubscription", reflect.TypeOf((*MockStore)(nil).DeletePushSubscription), ctx, id)
+}
+
 // DeleteScriptRun mocks base method.
 func (m *MockStore) DeleteScriptRun(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -333,6 +465,20 @@ func (mr *MockStoreMockRecorder) DeleteSession(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), ctx, id)
 }
 
+// DeleteSessionScratchObject mocks base method.
+func (m *MockStore) DeleteSessionScratchObject(ctx context.Context, sessionID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionScratchObject", ctx, sessionID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionScratchObject indicates an expected call of DeleteSessionScratchObject.
+func (mr *MockStoreMockRecorder) DeleteSessionScratchObject(ctx, sessionID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionScratchObject", reflect.TypeOf((*MockStore)(nil).DeleteSessionScratchObject), ctx, sessionID, key)
+}
+
 // DeleteSessionToolBinding mocks base method.
 func (m *MockStore) DeleteSessionToolBinding(ctx context.Context, sessionID, toolID string) error {
 	m.ctrl.T.Helper()
@@ -391,6 +537,36 @@ func (mr *MockStoreMockRecorder) GetAPIKey(ctx, apiKey any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockStore)(nil).GetAPIKey), ctx, apiKey)
 }
 
+// GetAgentMemory mocks base method.
+func (m *MockStore) GetAgentMemory(ctx context.Context, id string) (*types.AgentMemory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgentMemory", ctx, id)
+	ret0, _ := ret[0].(*types.AgentMemory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgentMemory indicates an expected call of GetAgentMemory.
+func (mr *MockStoreMockRecorder) GetAgentMemory(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgentMemory", reflect.TypeOf((*MockStore)(nil).GetAgentMemory), ctx, id)
+}
+
+// GetAgentPause mocks base method.
+func (m *MockStore) GetAgentPause(ctx context.Context, id string) (*types.AgentPause, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgentPause", ctx, id)
+	ret0, _ := ret[0].(*types.AgentPause)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgentPause indicates an expected call of GetAgentPause.
+func (mr *MockStoreMockRecorder) GetAgentPause(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgentPause", reflect.TypeOf((*MockStore)(nil).GetAgentPause), ctx, id)
+}
+
 // GetApp mocks base method.
 func (m *MockStore) GetApp(ctx context.Context, id string) (*types.App, error) {
 	m.ctrl.T.Helper()
@@ -466,6 +642,36 @@ func (mr *MockStoreMockRecorder) GetKnowledgeVersion(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnowledgeVersion", reflect.TypeOf((*MockStore)(nil).GetKnowledgeVersion), ctx, id)
 }
 
+// GetNotificationPreferences mocks base method.
+func (m *MockStore) GetNotificationPreferences(ctx context.Context, owner string) (*types.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", ctx, owner)
+	ret0, _ := ret[0].(*types.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockStoreMockRecorder) GetNotificationPreferences(ctx, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockStore)(nil).GetNotificationPreferences), ctx, owner)
+}
+
+// GetPushSubscription mocks base method.
+func (m *MockStore) GetPushSubscription(ctx context.Context, id string) (*types.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPushSubscription", ctx, id)
+	ret0, _ := ret[0].(*types.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPushSubscription indicates an expected call of GetPushSubscription.
+func (mr *MockStoreMockRecorder) GetPushSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPushSubscription", reflect.TypeOf((*MockStore)(nil).GetPushSubscription), ctx, id)
+}
+
 // GetSecret mocks base method.
 func (m *MockStore) GetSecret(ctx context.Context, id string) (*types.Secret, error) {
 	m.ctrl.T.Helper()
@@ -496,6 +702,21 @@ func (mr *MockStoreMockRecorder) GetSession(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), ctx, id)
 }
 
+// GetSessionScratchObject mocks base method.
+func (m *MockStore) GetSessionScratchObject(ctx context.Context, sessionID, key string) (*types.SessionScratchObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionScratchObject", ctx, sessionID, key)
+	ret0, _ := ret[0].(*types.SessionScratchObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionScratchObject indicates an expected call of GetSessionScratchObject.
+func (mr *MockStoreMockRecorder) GetSessionScratchObject(ctx, sessionID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionScratchObject", reflect.TypeOf((*MockStore)(nil).GetSessionScratchObject), ctx, sessionID, key)
+}
+
 // GetSessions mocks base method.
 func (m *MockStore) GetSessions(ctx context.Context, query GetSessionsQuery) ([]*types.Session, error) {
 	m.ctrl.T.Helper()
@@ -571,6 +792,36 @@ func (mr *MockStoreMockRecorder) ListAPIKeys(ctx, query any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), ctx, query)
 }
 
+// ListAgentMemories mocks base method.
+func (m *MockStore) ListAgentMemories(ctx context.Context, q *ListAgentMemoriesQuery) ([]*types.AgentMemory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAgentMemories", ctx, q)
+	ret0, _ := ret[0].([]*types.AgentMemory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAgentMemories indicates an expected call of ListAgentMemories.
+func (mr *MockStoreMockRecorder) ListAgentMemories(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgentMemories", reflect.TypeOf((*MockStore)(nil).ListAgentMemories), ctx, q)
+}
+
+// ListAppSessions mocks base method.
+func (m *MockStore) ListAppSessions(ctx context.Context, q *ListAppSessionsQuery) ([]*types.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppSessions", ctx, q)
+	ret0, _ := ret[0].([]*types.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppSessions indicates an expected call of ListAppSessions.
+func (mr *MockStoreMockRecorder) ListAppSessions(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppSessions", reflect.TypeOf((*MockStore)(nil).ListAppSessions), ctx, q)
+}
+
 // ListApps mocks base method.
 func (m *MockStore) ListApps(ctx context.Context, q *ListAppsQuery) ([]*types.App, error) {
 	m.ctrl.T.Helper()
@@ -601,6 +852,21 @@ func (mr *MockStoreMockRecorder) ListDataEntities(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataEntities", reflect.TypeOf((*MockStore)(nil).ListDataEntities), ctx, q)
 }
 
+// ListExpiredSessionScratchObjects mocks base method.
+func (m *MockStore) ListExpiredSessionScratchObjects(ctx context.Context, now time.Time) ([]*types.SessionScratchObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredSessionScratchObjects", ctx, now)
+	ret0, _ := ret[0].([]*types.SessionScratchObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredSessionScratchObjects indicates an expected call of ListExpiredSessionScratchObjects.
+func (mr *MockStoreMockRecorder) ListExpiredSessionScratchObjects(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredSessionScratchObjects", reflect.TypeOf((*MockStore)(nil).ListExpiredSessionScratchObjects), ctx, now)
+}
+
 // ListKnowledge mocks base method.
 func (m *MockStore) ListKnowledge(ctx context.Context, q *ListKnowledgeQuery) ([]*types.Knowledge, error) {
 	m.ctrl.T.Helper()
@@ -647,6 +913,36 @@ func (mr *MockStoreMockRecorder) ListLLMCalls(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLLMCalls", reflect.TypeOf((*MockStore)(nil).ListLLMCalls), ctx, q)
 }
 
+// ListLLMCallsForExport mocks base method.
+func (m *MockStore) ListLLMCallsForExport(ctx context.Context, q *ExportQuery) ([]*types.LLMCall, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLLMCallsForExport", ctx, q)
+	ret0, _ := ret[0].([]*types.LLMCall)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLLMCallsForExport indicates an expected call of ListLLMCallsForExport.
+func (mr *MockStoreMockRecorder) ListLLMCallsForExport(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLLMCallsForExport", reflect.TypeOf((*MockStore)(nil).ListLLMCallsForExport), ctx, q)
+}
+
+// ListPushSubscriptions mocks base method.
+func (m *MockStore) ListPushSubscriptions(ctx context.Context, owner string) ([]*types.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPushSubscriptions", ctx, owner)
+	ret0, _ := ret[0].([]*types.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPushSubscriptions indicates an expected call of ListPushSubscriptions.
+func (mr *MockStoreMockRecorder) ListPushSubscriptions(ctx, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPushSubscriptions", reflect.TypeOf((*MockStore)(nil).ListPushSubscriptions), ctx, owner)
+}
+
 // ListScriptRuns mocks base method.
 func (m *MockStore) ListScriptRuns(ctx context.Context, q *types.GptScriptRunsQuery) ([]*types.ScriptRun, error) {
 	m.ctrl.T.Helper()
@@ -662,6 +958,21 @@ func (mr *MockStoreMockRecorder) ListScriptRuns(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScriptRuns", reflect.TypeOf((*MockStore)(nil).ListScriptRuns), ctx, q)
 }
 
+// ListScriptRunsForExport mocks base method.
+func (m *MockStore) ListScriptRunsForExport(ctx context.Context, q *ExportQuery) ([]*types.ScriptRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScriptRunsForExport", ctx, q)
+	ret0, _ := ret[0].([]*types.ScriptRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScriptRunsForExport indicates an expected call of ListScriptRunsForExport.
+func (mr *MockStoreMockRecorder) ListScriptRunsForExport(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScriptRunsForExport", reflect.TypeOf((*MockStore)(nil).ListScriptRunsForExport), ctx, q)
+}
+
 // ListSecrets mocks base method.
 func (m *MockStore) ListSecrets(ctx context.Context, q *ListSecretsQuery) ([]*types.Secret, error) {
 	m.ctrl.T.Helper()
@@ -677,6 +988,21 @@ func (mr *MockStoreMockRecorder) ListSecrets(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockStore)(nil).ListSecrets), ctx, q)
 }
 
+// ListSessionScratchObjects mocks base method.
+func (m *MockStore) ListSessionScratchObjects(ctx context.Context, sessionID string) ([]*types.SessionScratchObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionScratchObjects", ctx, sessionID)
+	ret0, _ := ret[0].([]*types.SessionScratchObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessionScratchObjects indicates an expected call of ListSessionScratchObjects.
+func (mr *MockStoreMockRecorder) ListSessionScratchObjects(ctx, sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionScratchObjects", reflect.TypeOf((*MockStore)(nil).ListSessionScratchObjects), ctx, sessionID)
+}
+
 // ListSessionTools mocks base method.
 func (m *MockStore) ListSessionTools(ctx context.Context, sessionID string) ([]*types.Tool, error) {
 	m.ctrl.T.Helper()
@@ -692,6 +1018,36 @@ func (mr *MockStoreMockRecorder) ListSessionTools(ctx, sessionID any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionTools", reflect.TypeOf((*MockStore)(nil).ListSessionTools), ctx, sessionID)
 }
 
+// ListSessionsForArchive mocks base method.
+func (m *MockStore) ListSessionsForArchive(ctx context.Context, q *ListSessionsForArchiveQuery) ([]*types.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionsForArchive", ctx, q)
+	ret0, _ := ret[0].([]*types.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessionsForArchive indicates an expected call of ListSessionsForArchive.
+func (mr *MockStoreMockRecorder) ListSessionsForArchive(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionsForArchive", reflect.TypeOf((*MockStore)(nil).ListSessionsForArchive), ctx, q)
+}
+
+// ListSessionsForExport mocks base method.
+func (m *MockStore) ListSessionsForExport(ctx context.Context, q *ExportQuery) ([]*types.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionsForExport", ctx, q)
+	ret0, _ := ret[0].([]*types.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessionsForExport indicates an expected call of ListSessionsForExport.
+func (mr *MockStoreMockRecorder) ListSessionsForExport(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionsForExport", reflect.TypeOf((*MockStore)(nil).ListSessionsForExport), ctx, q)
+}
+
 // ListTools mocks base method.
 func (m *MockStore) ListTools(ctx context.Context, q *ListToolsQuery) ([]*types.Tool, error) {
 	m.ctrl.T.Helper()
@@ -722,6 +1078,66 @@ func (mr *MockStoreMockRecorder) LookupKnowledge(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupKnowledge", reflect.TypeOf((*MockStore)(nil).LookupKnowledge), ctx, q)
 }
 
+// SumLLMCallTokensBySession mocks base method.
+func (m *MockStore) SumLLMCallTokensBySession(ctx context.Context, q *ListAppSessionsQuery) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumLLMCallTokensBySession", ctx, q)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumLLMCallTokensBySession indicates an expected call of SumLLMCallTokensBySession.
+func (mr *MockStoreMockRecorder) SumLLMCallTokensBySession(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumLLMCallTokensBySession", reflect.TypeOf((*MockStore)(nil).SumLLMCallTokensBySession), ctx, q)
+}
+
+// TryAcquireLock mocks base method.
+func (m *MockStore) TryAcquireLock(ctx context.Context, key string) (func(), bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryAcquireLock", ctx, key)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TryAcquireLock indicates an expected call of TryAcquireLock.
+func (mr *MockStoreMockRecorder) TryAcquireLock(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryAcquireLock", reflect.TypeOf((*MockStore)(nil).TryAcquireLock), ctx, key)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockStore) UpdateAPIKeyLastUsed(ctx context.Context, apiKey string, usedAt time.Time, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", ctx, apiKey, usedAt, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAPIKeyLastUsed(ctx, apiKey, usedAt, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyLastUsed), ctx, apiKey, usedAt, ip)
+}
+
+// UpdateAgentMemory mocks base method.
+func (m *MockStore) UpdateAgentMemory(ctx context.Context, memory *types.AgentMemory) (*types.AgentMemory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAgentMemory", ctx, memory)
+	ret0, _ := ret[0].(*types.AgentMemory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAgentMemory indicates an expected call of UpdateAgentMemory.
+func (mr *MockStoreMockRecorder) UpdateAgentMemory(ctx, memory any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAgentMemory", reflect.TypeOf((*MockStore)(nil).UpdateAgentMemory), ctx, memory)
+}
+
 // UpdateApp mocks base method.
 func (m *MockStore) UpdateApp(ctx context.Context, tool *types.App) (*types.App, error) {
 	m.ctrl.T.Helper()
@@ -781,6 +1197,21 @@ func (mr *MockStoreMockRecorder) UpdateKnowledgeState(ctx, id, state, message, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKnowledgeState", reflect.TypeOf((*MockStore)(nil).UpdateKnowledgeState), ctx, id, state, message, percent)
 }
 
+// UpdateNotificationPreferences mocks base method.
+func (m *MockStore) UpdateNotificationPreferences(ctx context.Context, prefs *types.NotificationPreferences) (*types.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", ctx, prefs)
+	ret0, _ := ret[0].(*types.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockStoreMockRecorder) UpdateNotificationPreferences(ctx, prefs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockStore)(nil).UpdateNotificationPreferences), ctx, prefs)
+}
+
 // UpdateSecret mocks base method.
 func (m *MockStore) UpdateSecret(ctx context.Context, secret *types.Secret) (*types.Secret, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *PostgresStore) GetNotificationPreferences(ctx context.Context, owner string) (*types.NotificationPreferences, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	var prefs types.NotificationPreferences
+	err := s.gdb.WithContext(ctx).Where("owner = ?", owner).First(&prefs).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdateNotificationPreferences creates or replaces the owner's preferences
+func (s *PostgresStore) UpdateNotificationPreferences(ctx context.Context, prefs *types.NotificationPreferences) (*types.NotificationPreferences, error) {
+	if prefs.Owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	prefs.Created = time.Now()
+	prefs.Updated = prefs.Created
+
+	err := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated", "owner_type", "disabled_email_events", "disabled_web_push_events"}),
+	}).Create(prefs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetNotificationPreferences(ctx, prefs.Owner)
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *PostgresStoreTestSuite) TestNotificationPreferences() {
+	owner := "test-owner-" + system.GenerateUUID()
+
+	_, err := suite.db.GetNotificationPreferences(suite.ctx, owner)
+	require.ErrorIs(suite.T(), err, ErrNotFound)
+
+	created, err := suite.db.UpdateNotificationPreferences(suite.ctx, &types.NotificationPreferences{
+		Owner:                 owner,
+		OwnerType:             types.OwnerTypeUser,
+		DisabledWebPushEvents: types.StringArray{"session_complete"},
+	})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), types.StringArray{"session_complete"}, created.DisabledWebPushEvents)
+
+	// saving again replaces the previous preferences
+	updated, err := suite.db.UpdateNotificationPreferences(suite.ctx, &types.NotificationPreferences{
+		Owner:               owner,
+		OwnerType:           types.OwnerTypeUser,
+		DisabledEmailEvents: types.StringArray{"finetuning_started"},
+	})
+	require.NoError(suite.T(), err)
+	require.Empty(suite.T(), updated.DisabledWebPushEvents)
+	require.Equal(suite.T(), types.StringArray{"finetuning_started"}, updated.DisabledEmailEvents)
+	require.Equal(suite.T(), created.Created.Unix(), updated.Created.Unix())
+}
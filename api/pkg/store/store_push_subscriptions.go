@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/system"
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePushSubscription creates the subscription or, if the endpoint is
+// already subscribed, replaces its owner and keys
+func (s *PostgresStore) CreatePushSubscription(ctx context.Context, sub *types.PushSubscription) (*types.PushSubscription, error) {
+	if sub.Owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	if sub.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint not specified")
+	}
+
+	if sub.ID == "" {
+		sub.ID = system.GeneratePushSubscriptionID()
+	}
+
+	sub.Created = time.Now()
+	sub.Updated = sub.Created
+
+	err := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated", "owner", "owner_type", "p256dh", "auth", "user_agent"}),
+	}).Create(sub).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var created types.PushSubscription
+	err = s.gdb.WithContext(ctx).Where("endpoint = ?", sub.Endpoint).First(&created).Error
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (s *PostgresStore) GetPushSubscription(ctx context.Context, id string) (*types.PushSubscription, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id not specified")
+	}
+
+	var sub types.PushSubscription
+	err := s.gdb.WithContext(ctx).Where("id = ?", id).First(&sub).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (s *PostgresStore) ListPushSubscriptions(ctx context.Context, owner string) ([]*types.PushSubscription, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("owner not specified")
+	}
+
+	var subs []*types.PushSubscription
+	err := s.gdb.WithContext(ctx).Where("owner = ?", owner).Order("created DESC").Find(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (s *PostgresStore) DeletePushSubscription(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Delete(&types.PushSubscription{
+		ID: id,
+	}).Error
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+)
+
+type ListSessionsForArchiveQuery struct {
+	UpdatedBefore time.Time
+	Limit         int
+}
+
+// ArchiveSessionRequest replaces the interactions of a session with their
+// summary. Updated must match the stored row so that sessions that changed
+// since they were read are left alone.
+type ArchiveSessionRequest struct {
+	ID           string
+	Updated      time.Time
+	Interactions types.Interactions
+	ArchivedAt   time.Time
+}
+
+// ListSessionsForArchive returns the sessions, oldest first, that haven't been
+// updated since the cutoff and are not archived yet
+func (s *PostgresStore) ListSessionsForArchive(ctx context.Context, q *ListSessionsForArchiveQuery) ([]*types.Session, error) {
+	var sessions []*types.Session
+	err := s.gdb.WithContext(ctx).
+		Where("updated < ? AND archived_at IS NULL", q.UpdatedBefore).
+		Order("updated ASC").
+		Limit(q.Limit).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *PostgresStore) ArchiveSession(ctx context.Context, req *ArchiveSessionRequest) error {
+	if req.ID == "" {
+		return fmt.Errorf("id not specified")
+	}
+
+	// Update columns directly so the updated timestamp is preserved
+	res := s.gdb.WithContext(ctx).
+		Model(&types.Session{}).
+		Where("id = ? AND updated = ? AND archived_at IS NULL", req.ID, req.Updated).
+		UpdateColumns(map[string]interface{}{
+			"interactions": req.Interactions,
+			"archived_at":  req.ArchivedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
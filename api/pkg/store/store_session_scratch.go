@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helixml/helix/api/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSessionScratchObject creates or replaces the object with the same session and key
+func (s *PostgresStore) CreateSessionScratchObject(ctx context.Context, obj *types.SessionScratchObject) (*types.SessionScratchObject, error) {
+	if obj.SessionID == "" {
+		return nil, fmt.Errorf("session id not specified")
+	}
+	if obj.Key == "" {
+		return nil, fmt.Errorf("key not specified")
+	}
+
+	obj.Updated = time.Now()
+	if obj.Created.IsZero() {
+		obj.Created = obj.Updated
+	}
+
+	err := s.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated", "content_type", "size", "expires"}),
+	}).Create(obj).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetSessionScratchObject(ctx, obj.SessionID, obj.Key)
+}
+
+func (s *PostgresStore) GetSessionScratchObject(ctx context.Context, sessionID, key string) (*types.SessionScratchObject, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("session id not specified")
+	}
+
+	var obj types.SessionScratchObject
+	err := s.gdb.WithContext(ctx).Where("session_id = ? AND key = ?", sessionID, key).First(&obj).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (s *PostgresStore) ListSessionScratchObjects(ctx context.Context, sessionID string) ([]*types.SessionScratchObject, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("session id not specified")
+	}
+
+	var objs []*types.SessionScratchObject
+	err := s.gdb.WithContext(ctx).Where("session_id = ?", sessionID).Order("key ASC").Find(&objs).Error
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+func (s *PostgresStore) ListExpiredSessionScratchObjects(ctx context.Context, now time.Time) ([]*types.SessionScratchObject, error) {
+	var objs []*types.SessionScratchObject
+	err := s.gdb.WithContext(ctx).Where("expires < ?", now).Find(&objs).Error
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+func (s *PostgresStore) DeleteSessionScratchObject(ctx context.Context, sessionID, key string) error {
+	if sessionID == "" {
+		return fmt.Errorf("session id not specified")
+	}
+
+	return s.gdb.WithContext(ctx).Where("session_id = ? AND key = ?", sessionID, key).Delete(&types.SessionScratchObject{}).Error
+}
@@ -21,6 +21,8 @@ const (
 	KnowledgeVersionPrefix    = "knov_"
 	SecretPrefix              = "sec_"
 	TestRunPrefix             = "testrun_"
+	AgentMemoryPrefix         = "mem_"
+	PushSubscriptionPrefix    = "push_"
 )
 
 func GenerateUUID() string {
@@ -75,6 +77,14 @@ func GenerateSecretID() string {
 	return fmt.Sprintf("%s%s", SecretPrefix, newID())
 }
 
+func GenerateAgentMemoryID() string {
+	return fmt.Sprintf("%s%s", AgentMemoryPrefix, newID())
+}
+
+func GeneratePushSubscriptionID() string {
+	return fmt.Sprintf("%s%s", PushSubscriptionPrefix, newID())
+}
+
 // GenerateVersion generates a version string for the knowledge
 // This is used to identify the version of the knowledge
 // and to determine if the knowledge has been updated
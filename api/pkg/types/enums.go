@@ -277,6 +277,29 @@ const (
 	APIkeytypeApp APIKeyType = "app"
 )
 
+// APIKeyScope limits what an API key can be used for. A key without any scopes
+// has the same access as its owner.
+type APIKeyScope string
+
+const (
+	APIKeyScopeSessionsRead   APIKeyScope = "sessions:read"
+	APIKeyScopeSessionsWrite  APIKeyScope = "sessions:write"
+	APIKeyScopeAppsRead       APIKeyScope = "apps:read"
+	APIKeyScopeAppsWrite      APIKeyScope = "apps:write"
+	APIKeyScopeInferenceWrite APIKeyScope = "inference:write"
+	// everything the owner can do, including managing API keys
+	APIKeyScopeAdmin APIKeyScope = "admin"
+)
+
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeSessionsRead,
+	APIKeyScopeSessionsWrite,
+	APIKeyScopeAppsRead,
+	APIKeyScopeAppsWrite,
+	APIKeyScopeInferenceWrite,
+	APIKeyScopeAdmin,
+}
+
 type DataEntityType string
 
 const (
@@ -116,6 +116,39 @@ type RAGSettings struct {
 		APIKey     string `json:"api_key" yaml:"api_key"`
 		Collection string `json:"collection" yaml:"collection"`
 	} `json:"typesense" yaml:"typesense"`
+
+	// VectorStore keeps the index in a customer managed vector store instead
+	// of the built-in one
+	VectorStore VectorStoreSettings `json:"vector_store" yaml:"vector_store"`
+}
+
+type VectorStoreProvider string
+
+const (
+	VectorStoreProviderPGVector VectorStoreProvider = "pgvector"
+	VectorStoreProviderQdrant   VectorStoreProvider = "qdrant"
+	VectorStoreProviderWeaviate VectorStoreProvider = "weaviate"
+)
+
+// VectorStoreSettings configures a customer managed vector store. Helix computes
+// the embeddings through an OpenAI compatible embeddings endpoint and stores
+// them alongside the chunks. URLs and keys can reference the owner's secrets
+// as ${SECRET_NAME} so credentials are not stored with the knowledge.
+type VectorStoreSettings struct {
+	Provider VectorStoreProvider `json:"provider" yaml:"provider"`
+	// Qdrant or Weaviate base URL, or the Postgres connection string for pgvector
+	URL    string `json:"url" yaml:"url"`
+	APIKey string `json:"api_key" yaml:"api_key"`
+	// Qdrant collection, Weaviate class or pgvector table, defaults to helix_documents
+	// (HelixDocuments for Weaviate)
+	Collection string `json:"collection" yaml:"collection"`
+
+	Embeddings struct {
+		URL        string `json:"url" yaml:"url"` // OpenAI compatible base URL, e.g. https://api.openai.com/v1
+		APIKey     string `json:"api_key" yaml:"api_key"`
+		Model      string `json:"model" yaml:"model"`
+		Dimensions int    `json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+	} `json:"embeddings" yaml:"embeddings"`
 }
 
 func (r RAGSettings) Value() (driver.Value, error) {
@@ -384,6 +417,9 @@ type Session struct {
 	Owner string `json:"owner"`
 	// e.g. user, system, org
 	OwnerType OwnerType `json:"owner_type"`
+	// set when the interactions were moved to the session archive, only a
+	// summary of them is kept in the database until the session is rehydrated
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 func (s Session) TableName() string {
@@ -514,12 +550,77 @@ type APIKey struct {
 	Name      string          `json:"name"`
 	Type      APIKeyType      `json:"type" gorm:"default:api"`
 	AppID     *sql.NullString `json:"app_id"`
+	// empty means the key has the same access as its owner
+	Scopes StringArray `json:"scopes" gorm:"type:jsonb"`
+	// nil means the key never expires
+	ExpiresAt *time.Time `json:"expires_at"`
+	// IPs or CIDR ranges the key can be used from, empty means anywhere
+	IPAllowlist StringArray `json:"ip_allowlist" gorm:"type:jsonb"`
+	LastUsedAt  *time.Time  `json:"last_used_at"`
+	LastUsedIP  string      `json:"last_used_ip"`
 }
 
 func (APIKey) TableName() string {
 	return "api_key"
 }
 
+// HasScope returns true if the key is unscoped or has the scope (or admin)
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == string(scope) || s == string(APIKeyScopeAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// StringArray is stored as a json array
+type StringArray []string
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	j, err := json.Marshal(a)
+	return j, err
+}
+
+func (a *StringArray) Scan(src interface{}) error {
+	if src == nil {
+		// rows created before the column existed
+		*a = nil
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion .([]byte) failed")
+	}
+	var result StringArray
+	if err := json.Unmarshal(source, &result); err != nil {
+		return err
+	}
+	*a = result
+	return nil
+}
+
+func (StringArray) GormDataType() string {
+	return "json"
+}
+
+// CreateAPIKeyRequest is the body for creating an API key, all fields other
+// than name are optional
+type CreateAPIKeyRequest struct {
+	Name        string        `json:"name"`
+	Type        APIKeyType    `json:"type"`
+	AppID       string        `json:"app_id"`
+	Scopes      []APIKeyScope `json:"scopes"`
+	ExpiresAt   *time.Time    `json:"expires_at"`
+	IPAllowlist []string      `json:"ip_allowlist"`
+}
+
 type OwnerContext struct {
 	Owner     string
 	OwnerType OwnerType
@@ -571,6 +672,8 @@ type User struct {
 	Email    string
 	Username string
 	FullName string
+	// set if the token is an API key, used to enforce the key's scopes
+	APIKey *APIKey
 }
 
 // a single envelope that is broadcast to users
@@ -754,6 +857,18 @@ type DashboardData struct {
 	GlobalSchedulingDecisions []*GlobalSchedulingDecision `json:"global_scheduling_decisions"`
 }
 
+// SessionArchiveStats are counted since the server started. HitRate is the
+// fraction of session lookups that had to be rehydrated from the archive.
+type SessionArchiveStats struct {
+	ArchivedSessions  int64   `json:"archived_sessions"`
+	ArchivedBytes     int64   `json:"archived_bytes"`
+	SavedBytes        int64   `json:"saved_bytes"`
+	Lookups           int64   `json:"lookups"`
+	Rehydrations      int64   `json:"rehydrations"`
+	RehydrationErrors int64   `json:"rehydration_errors"`
+	HitRate           float64 `json:"hit_rate"`
+}
+
 type GlobalSchedulingDecision struct {
 	Created       time.Time     `json:"created"`
 	RunnerID      string        `json:"runner_id"`
@@ -1510,3 +1625,153 @@ type RunAPIActionResponse struct {
 	Response string `json:"response"` // Raw response from the API
 	Error    string `json:"error"`
 }
+
+// AgentPauseGlobalID is the ID of the instance-wide pause, which can only be
+// set or lifted by an admin
+const AgentPauseGlobalID = "global"
+
+// AgentPause is an emergency stop for LLM calls and tool execution. The ID is
+// either AgentPauseGlobalID or the ID of the owner whose agents are paused.
+type AgentPause struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Created   time.Time `json:"created"`
+	Owner     string    `json:"owner"`
+	OwnerType OwnerType `json:"owner_type"`
+	PausedBy  string    `json:"paused_by"`
+	Reason    string    `json:"reason"`
+}
+
+type AgentPauseRequest struct {
+	// Global pauses agents for every user, admin only
+	Global bool   `json:"global"`
+	Reason string `json:"reason"`
+}
+
+type AgentPauseStatus struct {
+	Paused bool        `json:"paused"`
+	Global *AgentPause `json:"global,omitempty"`
+	User   *AgentPause `json:"user,omitempty"`
+}
+
+// PushSubscription is a browser Web Push subscription. Endpoints are unique per
+// browser, subscribing again replaces the previous subscription.
+type PushSubscription struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Owner     string    `json:"owner" gorm:"index"`
+	OwnerType OwnerType `json:"owner_type"`
+	Endpoint  string    `json:"endpoint" gorm:"uniqueIndex"`
+	P256dh    string    `json:"-"`
+	Auth      string    `json:"-"`
+	UserAgent string    `json:"user_agent"`
+}
+
+// PushSubscriptionRequest is the JSON serialization of a browser PushSubscription
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// NotificationPreferences records the notifications a user has opted out of,
+// there is no row until the user changes something so everything is on by
+// default
+type NotificationPreferences struct {
+	Owner     string    `json:"owner" gorm:"primaryKey"`
+	OwnerType OwnerType `json:"owner_type"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	// Event names, e.g. "session_complete"
+	DisabledEmailEvents   StringArray `json:"disabled_email_events" gorm:"type:jsonb"`
+	DisabledWebPushEvents StringArray `json:"disabled_web_push_events" gorm:"type:jsonb"`
+}
+
+type WebPushConfig struct {
+	Enabled bool `json:"enabled"`
+	// Application server key to pass to pushManager.subscribe()
+	VAPIDPublicKey string `json:"vapid_public_key,omitempty"`
+}
+
+type AnalyticsInterval string
+
+const (
+	AnalyticsIntervalDay   AnalyticsInterval = "day"
+	AnalyticsIntervalWeek  AnalyticsInterval = "week"
+	AnalyticsIntervalMonth AnalyticsInterval = "month"
+)
+
+type AppAnalyticsQuery struct {
+	AppID    string
+	From     time.Time
+	To       time.Time
+	Interval AnalyticsInterval
+}
+
+// AppAnalytics aggregates the outcome of an app's sessions over a time range,
+// both in total and grouped into buckets of the requested interval
+type AppAnalytics struct {
+	AppID    string              `json:"app_id"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Interval AnalyticsInterval   `json:"interval"`
+	Total    *SessionAnalytics   `json:"total"`
+	Buckets  []*SessionAnalytics `json:"buckets"`
+}
+
+type SessionAnalytics struct {
+	Start             time.Time `json:"start"`
+	SessionsStarted   int       `json:"sessions_started"`
+	SessionsCompleted int       `json:"sessions_completed"`
+	SessionsFailed    int       `json:"sessions_failed"`
+	// sessions whose last interaction never finished
+	SessionsAbandoned int `json:"sessions_abandoned"`
+	// sessions whose interactions were moved to the session archive, only
+	// their last exchange is kept so their tool failures aren't counted
+	SessionsArchived int `json:"sessions_archived"`
+	// completed / (completed + failed + abandoned), sessions still in progress are excluded
+	SuccessRate            float64 `json:"success_rate"`
+	AverageDurationSeconds float64 `json:"average_duration_seconds"`
+	// average LLM tokens used per session, the closest thing to cost we record
+	AverageTokens float64             `json:"average_tokens"`
+	ToolFailures  []*ToolFailureCount `json:"tool_failures"`
+}
+
+type ToolFailureCount struct {
+	Tool  string `json:"tool"`
+	Count int    `json:"count"`
+}
+
+// SessionScratchObject is a temporary blob attached to a session, used by tools
+// and the frontend to pass intermediate artifacts around. The content lives in
+// the filestore, this is the metadata used for listing, quotas and expiry.
+type SessionScratchObject struct {
+	SessionID   string    `json:"session_id" gorm:"primaryKey"`
+	Key         string    `json:"key" gorm:"primaryKey"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	Owner       string    `json:"owner"`
+	OwnerType   OwnerType `json:"owner_type"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Expires     time.Time `json:"expires" gorm:"index"`
+}
+
+// AgentMemory is a persistent note (a fact about the codebase, a previous
+// decision, ...) that is added to the system prompt of every future session
+// the owner runs with the app
+type AgentMemory struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	AppID     string    `json:"app_id" gorm:"index"`
+	Owner     string    `json:"owner" gorm:"index"`
+	OwnerType OwnerType `json:"owner_type"`
+	Content   string    `json:"content"`
+}
+
+type AgentMemoryRequest struct {
+	Content string `json:"content"`
+}
@@ -0,0 +1,39 @@
+// Service worker for Helix web push notifications, the API sends
+// { title, body, url, tag } as the push message payload
+
+self.addEventListener('push', (event) => {
+  let data = {}
+  try {
+    data = event.data ? event.data.json() : {}
+  } catch (e) {
+    data = { body: event.data ? event.data.text() : '' }
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(data.title || 'Helix', {
+      body: data.body,
+      tag: data.tag,
+      icon: '/img/logo.png',
+      data: { url: data.url },
+    })
+  )
+})
+
+// focus the session if it's already open, otherwise open it
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close()
+
+  const url = event.notification.data && event.notification.data.url
+  if (!url) return
+
+  event.waitUntil(
+    self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then((clients) => {
+      for (const client of clients) {
+        if (client.url === url && 'focus' in client) {
+          return client.focus()
+        }
+      }
+      return self.clients.openWindow(url)
+    })
+  )
+})
@@ -0,0 +1,157 @@
+import React, { FC, useCallback } from 'react'
+import Box from '@mui/material/Box'
+import Button from '@mui/material/Button'
+import Checkbox from '@mui/material/Checkbox'
+import IconButton from '@mui/material/IconButton'
+import List from '@mui/material/List'
+import ListItem from '@mui/material/ListItem'
+import ListItemSecondaryAction from '@mui/material/ListItemSecondaryAction'
+import ListItemText from '@mui/material/ListItemText'
+import Paper from '@mui/material/Paper'
+import Table from '@mui/material/Table'
+import TableBody from '@mui/material/TableBody'
+import TableCell from '@mui/material/TableCell'
+import TableHead from '@mui/material/TableHead'
+import TableRow from '@mui/material/TableRow'
+import Typography from '@mui/material/Typography'
+import DeleteIcon from '@mui/icons-material/Delete'
+
+import useSnackbar from '../../hooks/useSnackbar'
+import { useWebPush, isWebPushSupported } from '../../hooks/useWebPush'
+
+import {
+  INotificationEvent,
+  INotificationPreferences,
+} from '../../types'
+
+const EVENTS: {
+  event: INotificationEvent,
+  label: string,
+  // session_complete happens on every response so it's never emailed
+  email: boolean,
+}[] = [{
+  event: 'approval_required',
+  label: 'A session needs your review before it can continue',
+  email: true,
+}, {
+  event: 'session_complete',
+  label: 'A response is ready',
+  email: false,
+}, {
+  event: 'finetuning_started',
+  label: 'Finetuning started',
+  email: true,
+}, {
+  event: 'finetuning_complete',
+  label: 'Finetuning complete',
+  email: true,
+}]
+
+const NotificationSettings: FC = () => {
+  const webPush = useWebPush()
+  const snackbar = useSnackbar()
+
+  React.useEffect(() => {
+    webPush.loadData()
+  }, [])
+
+  const handleSubscribe = useCallback(async () => {
+    try {
+      const subscribed = await webPush.subscribe()
+      if (subscribed) {
+        snackbar.success('Notifications enabled for this browser')
+      } else {
+        snackbar.error('Notifications were not enabled, check the browser allows them for this site')
+      }
+    } catch (e: any) {
+      snackbar.error(`Failed to enable notifications: ${e.message || e}`)
+    }
+  }, [webPush.subscribe])
+
+  const handleToggle = useCallback((field: keyof INotificationPreferences, event: INotificationEvent, enabled: boolean) => {
+    if (!webPush.preferences) return
+    const disabled = webPush.preferences[field].filter(e => e !== event)
+    if (!enabled) disabled.push(event)
+    webPush.updatePreferences({
+      ...webPush.preferences,
+      [field]: disabled,
+    })
+  }, [webPush.preferences, webPush.updatePreferences])
+
+  if (!webPush.config || !webPush.preferences) {
+    return null
+  }
+
+  const subscribedHere = webPush.subscriptions.some(s => s.endpoint === webPush.currentEndpoint)
+
+  return (
+    <Paper sx={{ mt: 2 }}>
+      <Typography sx={{ p: 2 }} variant="h6">Notifications</Typography>
+      <List>
+        {!webPush.config.enabled ? (
+          <ListItem>
+            <ListItemText secondary="Browser notifications are not enabled on this server." />
+          </ListItem>
+        ) : !isWebPushSupported() ? (
+          <ListItem>
+            <ListItemText secondary="This browser doesn't support push notifications. On iOS, add Helix to your home screen first." />
+          </ListItem>
+        ) : !subscribedHere && (
+          <ListItem>
+            <ListItemText
+              primary="Browser notifications"
+              secondary="Get notified on this device when a session needs you or a response is ready." />
+            <ListItemSecondaryAction>
+              <Button variant="contained" color="primary" onClick={handleSubscribe}>
+                Enable
+              </Button>
+            </ListItemSecondaryAction>
+          </ListItem>
+        )}
+        {webPush.subscriptions.map((subscription) => (
+          <ListItem key={subscription.id}>
+            <ListItemText
+              primary={subscription.endpoint === webPush.currentEndpoint ? 'This browser' : subscription.user_agent || 'Unknown browser'}
+              secondary={`subscribed ${new Date(subscription.created).toLocaleString()}`} />
+            <ListItemSecondaryAction>
+              <IconButton edge="end" aria-label="unsubscribe" onClick={() => webPush.unsubscribe(subscription)}>
+                <DeleteIcon />
+              </IconButton>
+            </ListItemSecondaryAction>
+          </ListItem>
+        ))}
+      </List>
+      <Box sx={{ px: 2, pb: 2 }}>
+        <Table size="small">
+          <TableHead>
+            <TableRow>
+              <TableCell>Notify me when</TableCell>
+              <TableCell align="center">Email</TableCell>
+              <TableCell align="center">Browser</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {EVENTS.map(({ event, label, email }) => (
+              <TableRow key={event}>
+                <TableCell>{label}</TableCell>
+                <TableCell align="center">
+                  <Checkbox
+                    disabled={!email}
+                    checked={email && !webPush.preferences?.disabled_email_events.includes(event)}
+                    onChange={(e) => handleToggle('disabled_email_events', event, e.target.checked)} />
+                </TableCell>
+                <TableCell align="center">
+                  <Checkbox
+                    checked={!webPush.preferences?.disabled_web_push_events.includes(event)}
+                    onChange={(e) => handleToggle('disabled_web_push_events', event, e.target.checked)} />
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </Box>
+    </Paper>
+  )
+}
+
+export default NotificationSettings
@@ -0,0 +1,123 @@
+import { useState, useCallback } from 'react'
+import useApi from './useApi'
+
+import {
+  IWebPushConfig,
+  IPushSubscription,
+  INotificationPreferences,
+} from '../types'
+
+// served from the assets folder so it can control the whole app
+const SERVICE_WORKER_URL = '/push-sw.js'
+
+// the API sends null for lists that were never set
+const normalizePreferences = (preferences: INotificationPreferences): INotificationPreferences => ({
+  disabled_email_events: preferences.disabled_email_events || [],
+  disabled_web_push_events: preferences.disabled_web_push_events || [],
+})
+
+// pushManager.subscribe() wants the VAPID key as bytes
+const urlBase64ToUint8Array = (base64String: string) => {
+  const padding = '='.repeat((4 - base64String.length % 4) % 4)
+  const base64 = (base64String + padding).replace(/-/g, '+').replace(/_/g, '/')
+  const raw = window.atob(base64)
+  return Uint8Array.from(raw, (c) => c.charCodeAt(0))
+}
+
+export const isWebPushSupported = () => {
+  return 'serviceWorker' in navigator && 'PushManager' in window && 'Notification' in window
+}
+
+export const useWebPush = () => {
+  const api = useApi()
+
+  const [config, setConfig] = useState<IWebPushConfig>()
+  const [subscriptions, setSubscriptions] = useState<IPushSubscription[]>([])
+  const [preferences, setPreferences] = useState<INotificationPreferences>()
+  // the endpoint of this browser's subscription, if it has one
+  const [currentEndpoint, setCurrentEndpoint] = useState<string>()
+
+  const loadData = useCallback(async () => {
+    const [configResult, subscriptionsResult, preferencesResult] = await Promise.all([
+      api.get<IWebPushConfig>(`/api/v1/notifications/web_push`, undefined, {
+        snackbar: true,
+      }),
+      api.get<IPushSubscription[]>(`/api/v1/notifications/web_push/subscriptions`, undefined, {
+        snackbar: true,
+      }),
+      api.get<INotificationPreferences>(`/api/v1/notifications/preferences`, undefined, {
+        snackbar: true,
+      }),
+    ])
+    if (configResult) setConfig(configResult)
+    if (subscriptionsResult) setSubscriptions(subscriptionsResult)
+    if (preferencesResult) setPreferences(normalizePreferences(preferencesResult))
+
+    if (isWebPushSupported()) {
+      const registration = await navigator.serviceWorker.getRegistration(SERVICE_WORKER_URL)
+      const subscription = await registration?.pushManager.getSubscription()
+      setCurrentEndpoint(subscription?.endpoint)
+    }
+  }, [api])
+
+  const subscribe = useCallback(async () => {
+    if (!config?.enabled || !config.vapid_public_key || !isWebPushSupported()) return false
+
+    const permission = await Notification.requestPermission()
+    if (permission !== 'granted') return false
+
+    const registration = await navigator.serviceWorker.register(SERVICE_WORKER_URL)
+    await navigator.serviceWorker.ready
+
+    const subscription = await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: urlBase64ToUint8Array(config.vapid_public_key),
+    })
+
+    const result = await api.post<PushSubscriptionJSON, IPushSubscription>(`/api/v1/notifications/web_push/subscriptions`, subscription.toJSON(), {}, {
+      snackbar: true,
+    })
+    if (!result) {
+      await subscription.unsubscribe()
+      return false
+    }
+    await loadData()
+    return true
+  }, [api, config, loadData])
+
+  // unsubscribe removes a subscription, when it belongs to this browser the
+  // browser is unsubscribed from the push service as well
+  const unsubscribe = useCallback(async (subscription: IPushSubscription) => {
+    await api.delete(`/api/v1/notifications/web_push/subscriptions/${subscription.id}`, {}, {
+      snackbar: true,
+    })
+
+    if (isWebPushSupported() && subscription.endpoint === currentEndpoint) {
+      const registration = await navigator.serviceWorker.getRegistration(SERVICE_WORKER_URL)
+      const browserSubscription = await registration?.pushManager.getSubscription()
+      await browserSubscription?.unsubscribe()
+    }
+    await loadData()
+  }, [api, currentEndpoint, loadData])
+
+  const updatePreferences = useCallback(async (updated: INotificationPreferences) => {
+    const result = await api.put<INotificationPreferences, INotificationPreferences>(`/api/v1/notifications/preferences`, updated, {}, {
+      snackbar: true,
+    })
+    if (!result) return
+    setPreferences(normalizePreferences(result))
+  }, [api])
+
+  return {
+    config,
+    subscriptions,
+    preferences,
+    currentEndpoint,
+    loadData,
+    subscribe,
+    unsubscribe,
+    updatePreferences,
+  }
+}
+
+export default useWebPush
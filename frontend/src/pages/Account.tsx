@@ -13,6 +13,7 @@ import Paper from '@mui/material/Paper'
 import Grid from '@mui/material/Grid'
 
 import Page from '../components/system/Page'
+import NotificationSettings from '../components/account/NotificationSettings'
 import DeleteIcon from '@mui/icons-material/Delete'
 import CopyIcon from '@mui/icons-material/CopyAll'
 
@@ -20,6 +21,26 @@ import useSnackbar from '../hooks/useSnackbar'
 import useAccount from '../hooks/useAccount'
 import useApi from '../hooks/useApi'
 
+import {
+  IApiKey,
+} from '../types'
+
+// the key itself plus any restrictions and when it was last used
+const describeApiKey = (apiKey: IApiKey) => {
+  const parts = [apiKey.key]
+  if (apiKey.scopes && apiKey.scopes.length > 0) {
+    parts.push(`scopes: ${apiKey.scopes.join(', ')}`)
+  }
+  if (apiKey.expires_at) {
+    parts.push(`expires ${new Date(apiKey.expires_at).toLocaleString()}`)
+  }
+  if (apiKey.ip_allowlist && apiKey.ip_allowlist.length > 0) {
+    parts.push(`allowed from: ${apiKey.ip_allowlist.join(', ')}`)
+  }
+  parts.push(apiKey.last_used_at ? `last used ${new Date(apiKey.last_used_at).toLocaleString()}${apiKey.last_used_ip ? ` from ${apiKey.last_used_ip}` : ''}` : 'never used')
+  return parts.join(' · ')
+}
+
 const Account: FC = () => {
   const account = useAccount()
   const api = useApi()
@@ -176,7 +197,7 @@ export HELIX_API_KEY=${apiKey}
                   </ListItem>
                     {account.apiKeys.map((apiKey) => (
                       <ListItem key={apiKey.key}>
-                        <ListItemText primary={apiKey.name} secondary={apiKey.key} />
+                        <ListItemText primary={apiKey.name} secondary={describeApiKey(apiKey)} />
                         <ListItemSecondaryAction>
                           <CopyToClipboard text={apiKey.key} onCopy={() => snackbar.success('Copied to clipboard')}>
                             <IconButton edge="end" aria-label="copy" sx={{ mr: 2 }}>
@@ -192,6 +213,8 @@ export HELIX_API_KEY=${apiKey}
                   </List>
                 </Paper>
 
+                <NotificationSettings />
+
                 <Paper sx={{ mt: 2 }}>
                   <Typography sx={{ p: 2}} variant="h6">CLI install &amp; login</Typography>
                   <List>
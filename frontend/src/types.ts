@@ -107,6 +107,30 @@ export interface IApiKey {
   name: string,
   app_id: string,
   type: IApiKeyType,
+  scopes?: string[],
+  expires_at?: string,
+  ip_allowlist?: string[],
+  last_used_at?: string,
+  last_used_ip?: string,
+}
+
+export interface IWebPushConfig {
+  enabled: boolean,
+  vapid_public_key?: string,
+}
+
+export interface IPushSubscription {
+  id: string,
+  created: string,
+  endpoint: string,
+  user_agent: string,
+}
+
+export type INotificationEvent = 'finetuning_started' | 'finetuning_complete' | 'session_complete' | 'approval_required'
+
+export interface INotificationPreferences {
+  disabled_email_events: INotificationEvent[],
+  disabled_web_push_events: INotificationEvent[],
 }
 
 export interface IFileStoreBreadcrumb {
@@ -235,6 +259,7 @@ export interface ISession {
   interactions: IInteraction[],
   owner: string,
   owner_type: IOwnerType,
+  archived_at?: string,
 }
 
 export interface IBotForm {
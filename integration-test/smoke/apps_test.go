@@ -17,13 +17,15 @@ func TestCreateRagApp(t *testing.T) {
 	t.Parallel()
 	ctx := helper.SetTestTimeout(t, 60*time.Second)
 
-	browser := createBrowser(ctx)
-	defer browser.MustClose()
+	run := helper.Track(t)
+	defer run.Recover()
+
+	browser := createBrowser(t, ctx)
 
 	page := browser.
 		DefaultDevice(devices.LaptopWithHiDPIScreen.Landscape()).
 		MustPage(helper.GetServerURL())
-	defer page.MustClose()
+	run.AttachPage(page)
 	page.MustWaitLoad()
 
 	err := helper.PerformLogin(t, page)
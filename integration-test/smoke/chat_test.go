@@ -15,11 +15,13 @@ func TestStartNewSession(t *testing.T) {
 	t.Parallel()
 	ctx := helper.SetTestTimeout(t, 30*time.Second)
 
-	browser := createBrowser(ctx)
-	defer browser.MustClose()
+	run := helper.Track(t)
+	defer run.Recover()
+
+	browser := createBrowser(t, ctx)
 
 	page := browser.MustPage(helper.GetServerURL())
-	defer page.MustClose()
+	run.AttachPage(page)
 	page.MustWaitLoad()
 
 	err := helper.PerformLogin(t, page)
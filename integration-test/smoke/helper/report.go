@@ -0,0 +1,376 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	TestStatusPassed  = "passed"
+	TestStatusFailed  = "failed"
+	TestStatusSkipped = "skipped"
+)
+
+// TestResult is the outcome of a tracked smoke test
+type TestResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Started   time.Time `json:"started"`
+	Duration  float64   `json:"duration_seconds"`
+	Failure   string    `json:"failure,omitempty"`
+	Steps     []string  `json:"steps"`
+	Artifacts []string  `json:"artifacts,omitempty"`
+}
+
+// Report collects the results of all tracked tests of the run, CI reads it
+// as JUnit XML (junit.xml) and JSON (report.json) from the artifacts directory
+type Report struct {
+	mu           sync.Mutex
+	artifactsDir string
+	started      time.Time
+	runs         map[string]*TestRun
+}
+
+var report = &Report{
+	started: time.Now(),
+	runs:    make(map[string]*TestRun),
+}
+
+// SetArtifactsDir enables saving reports, screenshots and browser console
+// logs to dir
+func SetArtifactsDir(dir string) {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	report.artifactsDir = dir
+}
+
+// TestRun tracks a single test for the report
+type TestRun struct {
+	t      *testing.T
+	mu     sync.Mutex
+	result TestResult
+	pages  []*trackedPage
+}
+
+type trackedPage struct {
+	index   int
+	console []string
+}
+
+// Track adds the test to the report, its steps and failure are recorded and,
+// with an artifacts directory, a screenshot and the console log of every
+// attached page are saved when the test finishes
+func Track(t *testing.T) *TestRun {
+	run := &TestRun{
+		t: t,
+		result: TestResult{
+			Name:    t.Name(),
+			Started: time.Now(),
+			Steps:   []string{},
+		},
+	}
+
+	report.mu.Lock()
+	report.runs[t.Name()] = run
+	report.mu.Unlock()
+
+	t.Cleanup(run.finish)
+
+	return run
+}
+
+func getRun(t *testing.T) *TestRun {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	return report.runs[t.Name()]
+}
+
+func (r *TestRun) addStep(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Steps = append(r.result.Steps, step)
+}
+
+func (r *TestRun) setFailure(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.result.Failure == "" {
+		r.result.Failure = message
+	}
+}
+
+// Recover turns a panic of the test, e.g. a rod Must* call failing because
+// the test timed out, into a failure of just this test. Use it as
+// defer run.Recover() so parallel tests and the report aren't taken down too.
+func (r *TestRun) Recover() {
+	if err := recover(); err != nil {
+		r.setFailure(fmt.Sprintf("%v", err))
+		r.t.Fatalf("❌ %v", err)
+	}
+}
+
+// AttachPage records the browser console of the page and closes it when the
+// test finishes, saving a screenshot and the console log first when there is
+// an artifacts directory
+func (r *TestRun) AttachPage(page *rod.Page) {
+	r.mu.Lock()
+	tp := &trackedPage{index: len(r.pages)}
+	r.pages = append(r.pages, tp)
+	r.mu.Unlock()
+
+	t := r.t
+	t.Cleanup(func() {
+		// The test context may already be done, e.g. when it timed out
+		page := page.Context(context.Background()).Timeout(10 * time.Second)
+
+		if dir := artifactsDir(); dir != "" {
+			r.savePageArtifacts(filepath.Join(dir, sanitizeTestName(t.Name())), tp, page)
+		}
+		if err := page.Close(); err != nil {
+			t.Logf("failed to close page: %v", err)
+		}
+	})
+
+	if err := (proto.RuntimeEnable{}).Call(page); err != nil {
+		r.t.Logf("failed to enable console logging: %v", err)
+		return
+	}
+
+	go page.EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			args = append(args, remoteObjectString(arg))
+		}
+		r.addConsole(tp, fmt.Sprintf("%s [%s] %s", time.Now().Format(time.RFC3339Nano), e.Type, strings.Join(args, " ")))
+	}, func(e *proto.RuntimeExceptionThrown) {
+		text := e.ExceptionDetails.Text
+		if e.ExceptionDetails.Exception != nil {
+			text = fmt.Sprintf("%s %s", text, remoteObjectString(e.ExceptionDetails.Exception))
+		}
+		r.addConsole(tp, fmt.Sprintf("%s [exception] %s", time.Now().Format(time.RFC3339Nano), text))
+	})()
+}
+
+func remoteObjectString(obj *proto.RuntimeRemoteObject) string {
+	if obj.Description != "" {
+		return obj.Description
+	}
+	if !obj.Value.Nil() {
+		return obj.Value.String()
+	}
+	return string(obj.Type)
+}
+
+func (r *TestRun) addConsole(tp *trackedPage, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tp.console = append(tp.console, line)
+}
+
+func (r *TestRun) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.result.Duration = time.Since(r.result.Started).Seconds()
+
+	switch {
+	case r.t.Skipped():
+		r.result.Status = TestStatusSkipped
+	case r.t.Failed():
+		r.result.Status = TestStatusFailed
+		if r.result.Failure == "" {
+			r.result.Failure = "test failed"
+			if len(r.result.Steps) > 0 {
+				r.result.Failure = fmt.Sprintf("test failed during step: %s", r.result.Steps[len(r.result.Steps)-1])
+			}
+		}
+	default:
+		r.result.Status = TestStatusPassed
+	}
+}
+
+func (r *TestRun) savePageArtifacts(dir string, tp *trackedPage, page *rod.Page) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		r.t.Logf("failed to create artifacts directory: %v", err)
+		return
+	}
+
+	screenshot, err := page.Screenshot(true, nil)
+	if err != nil {
+		r.t.Logf("failed to take screenshot: %v", err)
+	} else {
+		r.saveArtifact(filepath.Join(dir, fmt.Sprintf("page%d.png", tp.index)), screenshot)
+	}
+
+	r.mu.Lock()
+	console := strings.Join(tp.console, "\n")
+	r.mu.Unlock()
+
+	r.saveArtifact(filepath.Join(dir, fmt.Sprintf("page%d_console.log", tp.index)), []byte(console))
+}
+
+func (r *TestRun) saveArtifact(filename string, data []byte) {
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		r.t.Logf("failed to save %s: %v", filename, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Artifacts = append(r.result.Artifacts, filename)
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+func sanitizeTestName(name string) string {
+	return unsafeNameChars.ReplaceAllString(name, "_")
+}
+
+func artifactsDir() string {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	return report.artifactsDir
+}
+
+// Results returns the results of all tracked tests, sorted by name
+func Results() []TestResult {
+	report.mu.Lock()
+	runs := make([]*TestRun, 0, len(report.runs))
+	for _, run := range report.runs {
+		runs = append(runs, run)
+	}
+	report.mu.Unlock()
+
+	results := make([]TestResult, 0, len(runs))
+	for _, run := range runs {
+		run.mu.Lock()
+		results = append(results, run.result)
+		run.mu.Unlock()
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	return results
+}
+
+// WriteReports writes junit.xml and report.json to the artifacts directory,
+// it does nothing when no artifacts directory is set
+func WriteReports() error {
+	dir := artifactsDir()
+	if dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	results := Results()
+
+	bts, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), bts, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	bts, err = marshalJUnit("smoke", report.started, results)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "junit.xml"), bts, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func marshalJUnit(suiteName string, started time.Time, results []TestResult) ([]byte, error) {
+	suite := junitSuite{
+		Name:      suiteName,
+		Tests:     len(results),
+		Timestamp: started.UTC().Format(time.RFC3339),
+		Time:      fmt.Sprintf("%.3f", time.Since(started).Seconds()),
+	}
+
+	for _, result := range results {
+		tc := junitTestCase{
+			ClassName: suiteName,
+			Name:      result.Name,
+			Time:      fmt.Sprintf("%.3f", result.Duration),
+		}
+
+		var out strings.Builder
+		for _, step := range result.Steps {
+			fmt.Fprintf(&out, "%s\n", step)
+		}
+		// Jenkins and GitLab pick up attachments in this format
+		for _, artifact := range result.Artifacts {
+			fmt.Fprintf(&out, "[[ATTACHMENT|%s]]\n", artifact)
+		}
+		tc.SystemOut = out.String()
+
+		switch result.Status {
+		case TestStatusFailed:
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Message: result.Failure,
+				Text:    strings.Join(result.Steps, "\n"),
+			}
+		case TestStatusSkipped:
+			suite.Skipped++
+			tc.Skipped = &struct{}{}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	bts, err := xml.MarshalIndent(junitTestSuites{Suites: []junitSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), bts...), nil
+}
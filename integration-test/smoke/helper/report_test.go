@@ -0,0 +1,44 @@
+package helper
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJUnit(t *testing.T) {
+	results := []TestResult{
+		{Name: "TestCreateRagApp", Status: TestStatusFailed, Duration: 12.5, Failure: "App did not respond with the correct answer",
+			Steps: []string{"Browsing to the apps page", "Testing the app"}, Artifacts: []string{"artifacts/TestCreateRagApp/page0.png"}},
+		{Name: "TestInstallScript", Status: TestStatusSkipped, Steps: []string{}},
+		{Name: "TestStartNewSession", Status: TestStatusPassed, Duration: 3, Steps: []string{"Verifying login"}},
+	}
+
+	bts, err := marshalJUnit("smoke", time.Now(), results)
+	require.NoError(t, err)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(bts, &suites))
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	require.Equal(t, 3, suite.Tests)
+	require.Equal(t, 1, suite.Failures)
+	require.Equal(t, 1, suite.Skipped)
+	require.Len(t, suite.Cases, 3)
+
+	failed := suite.Cases[0]
+	require.Equal(t, "12.500", failed.Time)
+	require.NotNil(t, failed.Failure)
+	require.Equal(t, "App did not respond with the correct answer", failed.Failure.Message)
+	require.Contains(t, failed.SystemOut, "[[ATTACHMENT|artifacts/TestCreateRagApp/page0.png]]")
+
+	require.NotNil(t, suite.Cases[1].Skipped)
+	require.Nil(t, suite.Cases[2].Failure)
+}
+
+func TestSanitizeTestName(t *testing.T) {
+	require.Equal(t, "TestApps_create_rag_app", sanitizeTestName("TestApps/create rag app"))
+}
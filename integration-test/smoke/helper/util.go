@@ -19,10 +19,16 @@ const (
 
 func LogStep(t *testing.T, step string) {
 	t.Logf("⏩ %s", step)
+	if run := getRun(t); run != nil {
+		run.addStep(step)
+	}
 }
 
 func LogAndFail(t *testing.T, message string) {
 	t.Logf("❌ %s", message)
+	if run := getRun(t); run != nil {
+		run.setFailure(message)
+	}
 	t.FailNow()
 }
 
@@ -126,13 +132,33 @@ func SendMessage(t *testing.T, page *rod.Page) error {
 	return nil
 }
 
+var timeoutOverride time.Duration
+
+// SetTimeoutOverride replaces the timeout every test sets with SetTestTimeout,
+// zero keeps the tests' own
+func SetTimeoutOverride(timeout time.Duration) {
+	timeoutOverride = timeout
+}
+
+// SetTestTimeout returns the context for the test's browser, browser calls
+// fail once it times out. Defer TestRun.Recover so that only fails this test.
 func SetTestTimeout(t *testing.T, timeout time.Duration) context.Context {
+	if timeoutOverride > 0 {
+		timeout = timeoutOverride
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	t.Cleanup(cancel) // Register the cancel function to be called when the test finishes
 	go func() {
 		<-ctx.Done()
 		if ctx.Err() == context.DeadlineExceeded {
-			LogAndFail(t, "Test timed out")
+			// FailNow must not be called outside the test goroutine
+			message := fmt.Sprintf("Test timed out after %s", timeout)
+			t.Logf("❌ %s", message)
+			if run := getRun(t); run != nil {
+				run.setFailure(message)
+			}
+			t.Fail()
 		}
 	}()
 	return ctx
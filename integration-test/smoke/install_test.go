@@ -9,28 +9,28 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/helixml/helix/integration-test/smoke/helper"
 	"github.com/stretchr/testify/require"
 )
 
 func TestInstallScript(t *testing.T) {
 	t.Parallel()
+	helper.Track(t)
 
 	// Create temp dir for test
 	tmpDir, err := os.MkdirTemp("", "helix-install-test-*")
 	require.NoError(t, err)
 	defer os.RemoveAll(tmpDir)
 
-	// Change to temp dir
-	err = os.Chdir(tmpDir)
-	require.NoError(t, err)
-
-	// Download install script
+	// Download install script into the temp dir, not changing the working
+	// directory as that is shared with the tests running in parallel
 	downloadCmd := exec.Command("curl", "-sL", "-O", "https://get.helix.ml/install.sh")
+	downloadCmd.Dir = tmpDir
 	output, err := downloadCmd.CombinedOutput()
 	require.NoError(t, err, "Failed to download install script: %s", string(output))
 
 	// Verify script was downloaded
-	_, err = os.Stat("install.sh")
+	_, err = os.Stat(filepath.Join(tmpDir, "install.sh"))
 	require.NoError(t, err, "install.sh should exist")
 
 	// Create a shim for sudo that runs commands without sudo
@@ -51,6 +51,7 @@ func TestInstallScript(t *testing.T) {
 
 	// Run install script, using the shim for sudo
 	installCmd := exec.Command("bash", "install.sh", "-y", "--controlplane")
+	installCmd.Dir = tmpDir
 	installCmd.Env = append(os.Environ(), "PATH="+tmpDir+":"+os.Getenv("PATH"))
 	output, err = installCmd.CombinedOutput()
 	require.NoError(t, err, "Install script failed: %s", string(output))
@@ -5,41 +5,103 @@ package smoke
 
 import (
 	"context"
+	"flag"
+	"log"
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/helixml/helix/integration-test/smoke/helper"
 )
 
-// createBrowser creates a new browser instance for testing
-func createBrowser(ctx context.Context) *rod.Browser {
-	showBrowser := os.Getenv("SHOW_BROWSER")
-	externalBrowserURL := os.Getenv("BROWSER_URL")
-
-	var controlURL string
-	if externalBrowserURL != "" {
-		controlURL = externalBrowserURL
-	} else {
-		controlURL = launcher.New().
-			Headless(showBrowser == "").
-			MustLaunch()
-	}
+var (
+	browser     *rod.Browser
+	browserOnce sync.Once
+)
 
-	browser := rod.New().
-		ControlURL(controlURL).
-		Context(ctx).
-		MustConnect()
+// connectBrowser launches (or connects to) the browser shared by all tests
+func connectBrowser() *rod.Browser {
+	browserOnce.Do(func() {
+		showBrowser := os.Getenv("SHOW_BROWSER")
+		externalBrowserURL := os.Getenv("BROWSER_URL")
 
-	if externalBrowserURL != "" && showBrowser != "" {
-		launcher.Open(browser.ServeMonitor(""))
-	}
+		var controlURL string
+		if externalBrowserURL != "" {
+			controlURL = externalBrowserURL
+		} else {
+			controlURL = launcher.New().
+				Headless(showBrowser == "").
+				MustLaunch()
+		}
+
+		browser = rod.New().
+			ControlURL(controlURL).
+			MustConnect()
+
+		if externalBrowserURL != "" && showBrowser != "" {
+			launcher.Open(browser.ServeMonitor(""))
+		}
+	})
 
 	return browser
 }
 
+// createBrowser creates an isolated browser context for the test, with its
+// own cookies and storage, so tests can run in parallel against the shared
+// browser. It is disposed of when the test finishes.
+func createBrowser(t *testing.T, ctx context.Context) *rod.Browser {
+	incognito := connectBrowser().MustIncognito()
+	t.Cleanup(func() {
+		if err := incognito.Close(); err != nil {
+			t.Logf("failed to close browser context: %v", err)
+		}
+	})
+
+	return incognito.Context(ctx)
+}
+
+// Pass these after -args, e.g.
+// go test -tags=integration ./integration-test/smoke -args -filter=RagApp -artifacts=/tmp/smoke
+var (
+	filter       = flag.String("filter", "", "Only run the smoke tests matching this regexp")
+	artifactsDir = flag.String("artifacts", "", "Directory to write JUnit/JSON reports, screenshots and browser console logs to")
+	parallel     = flag.Int("parallel", 0, "Run up to N smoke tests at the same time, defaults to GOMAXPROCS")
+	testTimeout  = flag.Duration("test-timeout", 0, "Timeout of each smoke test, defaults to the test's own")
+)
+
 func TestMain(m *testing.M) {
-	// Just run the tests - each test will create its own browser
+	flag.Parse()
+
+	if *filter != "" {
+		if err := flag.Set("test.run", *filter); err != nil {
+			log.Fatalf("invalid -filter: %v", err)
+		}
+	}
+	if *parallel > 0 {
+		if err := flag.Set("test.parallel", strconv.Itoa(*parallel)); err != nil {
+			log.Fatalf("invalid -parallel: %v", err)
+		}
+	}
+	helper.SetArtifactsDir(*artifactsDir)
+	helper.SetTimeoutOverride(*testTimeout)
+
 	code := m.Run()
+
+	if browser != nil {
+		if err := browser.Close(); err != nil {
+			log.Printf("failed to close browser: %v", err)
+		}
+	}
+
+	if err := helper.WriteReports(); err != nil {
+		log.Printf("failed to write smoke test reports: %v", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+
 	os.Exit(code)
 }